@@ -19,10 +19,12 @@ package v1alpha1
 import (
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ApplicationSet is a set of Application resources
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 type ApplicationSet struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
@@ -36,6 +38,75 @@ type ApplicationSetSpec struct {
 	Generators []ApplicationSetGenerator `json:"generators"`
 	Template   ApplicationSetTemplate    `json:"template"`
 	SyncPolicy *ApplicationSetSyncPolicy `json:"syncPolicy,omitempty"`
+	// GoTemplate renders the template with Go's text/template instead of the default {{param}} substitution. Every
+	// string of the template is rendered on its own, with the parameters as data and a library of Sprig-style
+	// functions; referencing a missing parameter is an error.
+	GoTemplate bool `json:"goTemplate,omitempty"`
+	// IsolateGeneratorErrors keeps a failing generator from blocking the others: the Applications of the generators
+	// that succeed are created, updated and deleted, while those of the generators that fail are left untouched.
+	// Applications are annotated with the hash of their generator to tell them apart, see AnnotationGeneratorHash.
+	IsolateGeneratorErrors bool `json:"isolateGeneratorErrors,omitempty"`
+	// Strategy configures how changes are applied to the generated Applications, when the controller policy allows
+	// updating them. By default every Application is created and updated at once.
+	Strategy *ApplicationSetStrategy `json:"strategy,omitempty"`
+	// PreservedFields lists the annotations and labels of the Applications that are managed outside of the
+	// ApplicationSet: their live values are kept when the Applications are updated. The other annotations and labels
+	// are those of the template.
+	PreservedFields *ApplicationPreservedFields `json:"preservedFields,omitempty"`
+	// IgnoreApplicationDifferences lists the fields of the Applications that are managed outside of the
+	// ApplicationSet: their live values are kept when the Applications are updated.
+	IgnoreApplicationDifferences ApplicationSetIgnoreDifferences `json:"ignoreApplicationDifferences,omitempty"`
+}
+
+// ApplicationPreservedFields lists annotation and label keys of the Applications.
+type ApplicationPreservedFields struct {
+	Annotations []string `json:"annotations,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// ApplicationSetIgnoreDifferences lists the fields of the Applications that are managed outside of the ApplicationSet.
+type ApplicationSetIgnoreDifferences []ApplicationSetResourceIgnoreDifferences
+
+// ApplicationSetResourceIgnoreDifferences lists fields of the spec of the Applications by their path, e.g.
+// /spec/source/targetRevision as a JSON pointer, or .spec.source.helm.parameters[].value as a JQ-like path expression,
+// where [] selects every element of a list.
+type ApplicationSetResourceIgnoreDifferences struct {
+	// Name restricts the fields to the Application of the given name. The fields apply to every Application if empty.
+	Name              string   `json:"name,omitempty"`
+	JSONPointers      []string `json:"jsonPointers,omitempty"`
+	JQPathExpressions []string `json:"jqPathExpressions,omitempty"`
+}
+
+// ApplicationSetStrategy configures how changes are applied to the generated Applications.
+type ApplicationSetStrategy struct {
+	// Type is AllAtOnce (the default) or RollingUpdate.
+	Type string `json:"type,omitempty"`
+	// RollingUpdate configures the RollingUpdate strategy.
+	RollingUpdate *ApplicationSetRollingUpdateStrategy `json:"rollingUpdate,omitempty"`
+}
+
+const (
+	// ApplicationSetStrategyTypeAllAtOnce creates and updates every Application at once.
+	ApplicationSetStrategyTypeAllAtOnce = "AllAtOnce"
+	// ApplicationSetStrategyTypeRollingUpdate creates and updates the Applications one step at a time.
+	ApplicationSetStrategyTypeRollingUpdate = "RollingUpdate"
+)
+
+// ApplicationSetRollingUpdateStrategy groups the generated Applications into ordered steps. The Applications of a
+// step are only created or updated once every Application of the previous steps is up to date, Synced and Healthy.
+// Applications not selected by any step are rolled out in an implicit last step.
+type ApplicationSetRollingUpdateStrategy struct {
+	Steps []ApplicationSetRolloutStep `json:"steps"`
+}
+
+// ApplicationSetRolloutStep is a step of a RollingUpdate.
+type ApplicationSetRolloutStep struct {
+	// Selector selects the Applications of the step by their labels. Applications selected by several steps belong
+	// to the first one.
+	Selector metav1.LabelSelector `json:"selector"`
+	// MaxUnavailable is the maximum number, or percentage, of Applications of the step that can be updated and not
+	// yet Synced and Healthy at the same time. It defaults to 100%, updating the whole step at once.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // ApplicationSetSyncPolicy configures how generated Applications will relate to their
@@ -43,8 +114,50 @@ type ApplicationSetSpec struct {
 type ApplicationSetSyncPolicy struct {
 	// SkipPrune will disable the default behavior which will delete Applications that are no longer being generated for the ApplicationSet which created them, or the ApplicationSet itself is deleted. If SkipPrune is set to true, these Applications will be orphaned but continue to exist.
 	SkipPrune bool `json:"skipPrune,omitempty"`
+	// ApplicationsSync restricts the changes the controller applies to the Applications of the ApplicationSet:
+	// create-only, create-update (no deletion), create-delete (no update) or sync (the default). It can't allow more
+	// than the --policy of the controller.
+	ApplicationsSync *ApplicationsSyncPolicy `json:"applicationsSync,omitempty"`
+	// DeletionSafeguards blocks the deletion of the Applications that are no longer generated when it looks like a
+	// mistake, until it is acknowledged with the AnnotationAcknowledgeDeletion annotation.
+	DeletionSafeguards *ApplicationSetDeletionSafeguards `json:"deletionSafeguards,omitempty"`
+	// SelfHeal reverts the manual changes to the fields of the Applications managed by the ApplicationSet, which is
+	// the default. When false, the Applications changed manually are no longer updated, and are listed in
+	// status.driftedApplications instead.
+	SelfHeal *bool `json:"selfHeal,omitempty"`
+}
+
+// ApplicationSetDeletionSafeguards configures when the deletion of Applications is blocked.
+type ApplicationSetDeletionSafeguards struct {
+	// MaxDeletions is the maximum number, or percentage of the current Applications, that can be deleted in a single
+	// reconciliation.
+	MaxDeletions *intstr.IntOrString `json:"maxDeletions,omitempty"`
+	// RefuseEmptyGeneration blocks the deletion when the generators don't generate any Application.
+	RefuseEmptyGeneration bool `json:"refuseEmptyGeneration,omitempty"`
 }
 
+// AnnotationGeneratorHash is set on the generated Applications, when IsolateGeneratorErrors is true, to the hash of the
+// spec of the generator of the Application, so that reordering spec.generators doesn't mix up their Applications.
+const AnnotationGeneratorHash = "applicationset.argoproj.io/generator-hash"
+
+// AnnotationApplicationHash is set on the generated Applications, when SelfHeal is false, to the hash of the fields
+// managed by the ApplicationSet as last applied by the controller, to detect their manual changes.
+const AnnotationApplicationHash = "applicationset.argoproj.io/application-hash"
+
+// AnnotationAcknowledgeDeletion, set to "true" on an ApplicationSet, allows a deletion blocked by its
+// DeletionSafeguards. The controller removes the annotation once the Applications are deleted.
+const AnnotationAcknowledgeDeletion = "applicationset.argoproj.io/acknowledge-deletion"
+
+// ApplicationsSyncPolicy is the policy applied to the Applications of an ApplicationSet.
+type ApplicationsSyncPolicy string
+
+const (
+	ApplicationsSyncPolicyCreateOnly   ApplicationsSyncPolicy = "create-only"
+	ApplicationsSyncPolicyCreateUpdate ApplicationsSyncPolicy = "create-update"
+	ApplicationsSyncPolicyCreateDelete ApplicationsSyncPolicy = "create-delete"
+	ApplicationsSyncPolicySync         ApplicationsSyncPolicy = "sync"
+)
+
 // ApplicationSetTemplate represents argocd ApplicationSpec
 type ApplicationSetTemplate struct {
 	metav1.ObjectMeta `json:"metadata"`
@@ -53,15 +166,57 @@ type ApplicationSetTemplate struct {
 
 // ApplicationSetGenerator include list item info
 type ApplicationSetGenerator struct {
-	List     *ListGenerator    `json:"list,omitempty"`
-	Clusters *ClusterGenerator `json:"clusters,omitempty"`
-	Git      *GitGenerator     `json:"git,omitempty"`
+	List                    *ListGenerator        `json:"list,omitempty"`
+	Clusters                *ClusterGenerator     `json:"clusters,omitempty"`
+	Git                     *GitGenerator         `json:"git,omitempty"`
+	Matrix                  *MatrixGenerator      `json:"matrix,omitempty"`
+	Merge                   *MergeGenerator       `json:"merge,omitempty"`
+	PullRequest             *PullRequestGenerator `json:"pullRequest,omitempty"`
+	SCMProvider             *SCMProviderGenerator `json:"scmProvider,omitempty"`
+	ClusterDecisionResource *DuckTypeGenerator    `json:"clusterDecisionResource,omitempty"`
+	Plugin                  *PluginGenerator      `json:"plugin,omitempty"`
+}
+
+// ApplicationSetNestedGenerator represents a generator nested within a combination-type generator (MatrixGenerator, MergeGenerator).
+// Combination-type generators cannot themselves be nested, so only the basic generators are available here.
+type ApplicationSetNestedGenerator struct {
+	List                    *ListGenerator        `json:"list,omitempty"`
+	Clusters                *ClusterGenerator     `json:"clusters,omitempty"`
+	Git                     *GitGenerator         `json:"git,omitempty"`
+	PullRequest             *PullRequestGenerator `json:"pullRequest,omitempty"`
+	SCMProvider             *SCMProviderGenerator `json:"scmProvider,omitempty"`
+	ClusterDecisionResource *DuckTypeGenerator    `json:"clusterDecisionResource,omitempty"`
+	Plugin                  *PluginGenerator      `json:"plugin,omitempty"`
+}
+
+// ToApplicationSetGenerator converts the nested generator into an ApplicationSetGenerator, so that it can be
+// handed to the same Generator implementations as a top-level generator.
+func (g ApplicationSetNestedGenerator) ToApplicationSetGenerator() *ApplicationSetGenerator {
+	return &ApplicationSetGenerator{
+		List:                    g.List,
+		Clusters:                g.Clusters,
+		Git:                     g.Git,
+		PullRequest:             g.PullRequest,
+		SCMProvider:             g.SCMProvider,
+		ClusterDecisionResource: g.ClusterDecisionResource,
+		Plugin:                  g.Plugin,
+	}
+}
+
+// GeneratorFilter restricts the parameter sets produced by a generator. The expression is evaluated against every
+// parameter set, which is kept only if the expression is true. Parameters are referenced with the template syntax,
+// e.g. {{name}} matches "sales-.*" or {{metadata.labels.environment}} in [staging, prod].
+type GeneratorFilter struct {
+	Expr string `json:"expr"`
 }
 
 // ListGenerator include items info
 type ListGenerator struct {
 	Elements []ListGeneratorElement `json:"elements"`
 	Template ApplicationSetTemplate `json:"template,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
 }
 
 // ListGeneratorElement include cluster and url info
@@ -82,6 +237,58 @@ type ClusterGenerator struct {
 
 	// Values contains key/value pairs which are passed directly as parameters to the template
 	Values map[string]string `json:"values,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
+}
+
+// DuckTypeGenerator defines a generator to match against clusters chosen by an external placement engine. The
+// decisions are read from the status of an arbitrary ("duck-typed") resource in the namespace of the ApplicationSet,
+// and matched against the clusters registered with ArgoCD.
+type DuckTypeGenerator struct {
+	// ConfigMapRef is the name of the ConfigMap describing the resource: its apiVersion, its resource (plural) name,
+	// the statusListKey of the list of decisions in its status, and the matchKey naming the cluster in each decision.
+	ConfigMapRef string `json:"configMapRef"`
+	// Name is the name of the resource to read. Either Name or LabelSelector must be set.
+	Name string `json:"name,omitempty"`
+	// LabelSelector selects the resources to read, when Name is not set. The decisions of all the selected
+	// resources are combined.
+	LabelSelector metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// RequeueAfterSeconds is an optional interval at which the decisions are read again. The ApplicationSet is also
+	// requeued whenever the resource changes.
+	RequeueAfterSeconds int64                  `json:"requeueAfterSeconds,omitempty"`
+	Template            ApplicationSetTemplate `json:"template,omitempty"`
+
+	// Values contains key/value pairs which are passed directly as parameters to the template
+	Values map[string]string `json:"values,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
+}
+
+// MatrixGenerator generates the cartesian product of the parameters produced by two child generators.
+// Every parameter set of the first generator is combined with every parameter set of the second one. A key
+// may be produced by both generators only if both produce the same value for it. Templates set on the child
+// generators are ignored, use the Template of the MatrixGenerator instead.
+type MatrixGenerator struct {
+	Generators []ApplicationSetNestedGenerator `json:"generators"`
+	Template   ApplicationSetTemplate          `json:"template,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
+}
+
+// MergeGenerator merges the parameters produced by two or more child generators. The first generator is the base:
+// parameter sets produced by the following generators override the base parameter set that has the same values
+// for all the MergeKeys. Parameter sets that don't match any base parameter set are ignored, so the generator
+// never produces more parameter sets than the base generator does.
+type MergeGenerator struct {
+	Generators []ApplicationSetNestedGenerator `json:"generators"`
+	MergeKeys  []string                        `json:"mergeKeys"`
+	Template   ApplicationSetTemplate          `json:"template,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
 }
 
 type GitGenerator struct {
@@ -91,20 +298,322 @@ type GitGenerator struct {
 	Revision            string                      `json:"revision"`
 	RequeueAfterSeconds int64                       `json:"requeueAfterSeconds,omitempty"`
 	Template            ApplicationSetTemplate      `json:"template,omitempty"`
+
+	// DirectoryDiscovery is Apps (the default), matching the directories detected as applications by the Argo CD repo
+	// server, e.g. Helm charts and Kustomizations, or All, matching every directory of the repository.
+	DirectoryDiscovery string `json:"directoryDiscovery,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
 }
 
+const (
+	// GitDirectoryDiscoveryApps matches the directories detected as applications by the Argo CD repo server.
+	GitDirectoryDiscoveryApps = "Apps"
+	// GitDirectoryDiscoveryAll matches every directory of the repository.
+	GitDirectoryDiscoveryAll = "All"
+)
+
+// GitDirectoryGeneratorItem selects the directories matching a glob pattern, in which "**" matches any number of
+// directories. The directories matching an excluded item are never selected.
 type GitDirectoryGeneratorItem struct {
 	Path string `json:"path"`
+	// Exclude excludes the directories matching the path instead, whatever the other items
+	Exclude bool `json:"exclude,omitempty"`
 }
 
+// GitFileGeneratorItem selects the files matching a glob pattern, in which "**" matches any number of directories.
 type GitFileGeneratorItem struct {
 	Path string `json:"path"`
 }
 
+// PullRequestGenerator defines a generator that lists the open pull requests of a repository, using the API of
+// the SCM provider hosting it. Exactly one SCM provider must be configured.
+type PullRequestGenerator struct {
+	Github *PullRequestGeneratorGithub `json:"github,omitempty"`
+	GitLab *PullRequestGeneratorGitLab `json:"gitlab,omitempty"`
+	Gitea  *PullRequestGeneratorGitea  `json:"gitea,omitempty"`
+	// RequeueAfterSeconds is the interval at which the SCM provider is polled for pull requests. Defaults to 30 minutes.
+	RequeueAfterSeconds int64                  `json:"requeueAfterSeconds,omitempty"`
+	Template            ApplicationSetTemplate `json:"template,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
+}
+
+// PullRequestGeneratorGithub defines a connection to GitHub (or GitHub Enterprise) for the PullRequestGenerator.
+type PullRequestGeneratorGithub struct {
+	// Owner is the GitHub organization or user owning the repository.
+	Owner string `json:"owner"`
+	// Repo is the name of the repository.
+	Repo string `json:"repo"`
+	// API is the GitHub API URL to use, e.g. for GitHub Enterprise. Defaults to https://api.github.com.
+	API string `json:"api,omitempty"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Labels restricts the pull requests to those carrying all of the given labels.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// PullRequestGeneratorGitLab defines a connection to GitLab for the PullRequestGenerator.
+type PullRequestGeneratorGitLab struct {
+	// Project is the ID or the full path (e.g. "group/project") of the GitLab project.
+	Project string `json:"project"`
+	// API is the GitLab URL to use, e.g. for a self-hosted instance. Defaults to https://gitlab.com.
+	API string `json:"api,omitempty"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Labels restricts the merge requests to those carrying all of the given labels.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// PullRequestGeneratorGitea defines a connection to Gitea for the PullRequestGenerator.
+type PullRequestGeneratorGitea struct {
+	// Owner is the Gitea organization or user owning the repository.
+	Owner string `json:"owner"`
+	// Repo is the name of the repository.
+	Repo string `json:"repo"`
+	// API is the URL of the Gitea instance, e.g. https://gitea.mydomain.com.
+	API string `json:"api"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Insecure allows self-signed TLS certificates.
+	Insecure bool `json:"insecure,omitempty"`
+	// Labels restricts the pull requests to those carrying all of the given labels.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// SCMProviderGenerator defines a generator that discovers the repositories of an organization, using the API of
+// the SCM provider hosting it. Exactly one SCM provider must be configured.
+type SCMProviderGenerator struct {
+	Github *SCMProviderGeneratorGithub `json:"github,omitempty"`
+	Gitlab *SCMProviderGeneratorGitlab `json:"gitlab,omitempty"`
+	Gitea  *SCMProviderGeneratorGitea  `json:"gitea,omitempty"`
+	// CloneProtocol is the protocol of the url parameter, either "https" (the default) or "ssh".
+	CloneProtocol string `json:"cloneProtocol,omitempty"`
+	// Filters restrict the discovered repositories. A repository is kept if it matches any of the filters; without
+	// filters every repository is kept.
+	Filters []SCMProviderGeneratorFilter `json:"filters,omitempty"`
+	// RequeueAfterSeconds is the interval at which the SCM provider is polled for repositories. Defaults to 30 minutes.
+	RequeueAfterSeconds int64                  `json:"requeueAfterSeconds,omitempty"`
+	Template            ApplicationSetTemplate `json:"template,omitempty"`
+}
+
+// SCMProviderGeneratorGithub defines a connection to GitHub (or GitHub Enterprise) for the SCMProviderGenerator.
+type SCMProviderGeneratorGithub struct {
+	// Organization is the GitHub organization to scan.
+	Organization string `json:"organization"`
+	// API is the GitHub API URL to use, e.g. for GitHub Enterprise. Defaults to https://api.github.com.
+	API string `json:"api,omitempty"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// AllBranches discovers every branch of every repository, instead of only the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorGitlab defines a connection to GitLab for the SCMProviderGenerator.
+type SCMProviderGeneratorGitlab struct {
+	// Group is the ID or the full path of the GitLab group to scan.
+	Group string `json:"group"`
+	// IncludeSubgroups also scans the subgroups of the group.
+	IncludeSubgroups bool `json:"includeSubgroups,omitempty"`
+	// API is the GitLab URL to use, e.g. for a self-hosted instance. Defaults to https://gitlab.com.
+	API string `json:"api,omitempty"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// AllBranches discovers every branch of every repository, instead of only the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorGitea defines a connection to Gitea for the SCMProviderGenerator.
+type SCMProviderGeneratorGitea struct {
+	// Owner is the Gitea organization to scan.
+	Owner string `json:"owner"`
+	// API is the URL of the Gitea instance, e.g. https://gitea.mydomain.com.
+	API string `json:"api"`
+	// TokenRef references the Secret containing the authentication token. Anonymous access is used if not set.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// Insecure allows self-signed TLS certificates.
+	Insecure bool `json:"insecure,omitempty"`
+	// AllBranches discovers every branch of every repository, instead of only the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorFilter restricts the repositories discovered by the SCMProviderGenerator. All the conditions
+// set on a filter must match for a repository to pass it.
+type SCMProviderGeneratorFilter struct {
+	// RepositoryMatch is a regular expression the repository name must match.
+	RepositoryMatch *string `json:"repositoryMatch,omitempty"`
+	// BranchMatch is a regular expression the branch name must match.
+	BranchMatch *string `json:"branchMatch,omitempty"`
+	// PathsExist lists paths that must all exist in the repository at the discovered branch.
+	PathsExist []string `json:"pathsExist,omitempty"`
+	// Expr is a filter expression the parameters of the repository must match, as for the Filters of the
+	// other generators.
+	Expr *string `json:"expr,omitempty"`
+}
+
+// PluginGenerator defines a generator that fetches its parameters from an external HTTP service (a plugin).
+type PluginGenerator struct {
+	// ConfigMapRef is the name of the ConfigMap describing the plugin: its baseUrl, the tokenSecretName and
+	// tokenSecretKey of the Secret holding its bearer token, and an optional requestTimeout in seconds.
+	ConfigMapRef string `json:"configMapRef"`
+	// Input is sent to the plugin along with the request.
+	Input PluginInput `json:"input,omitempty"`
+	// RequeueAfterSeconds is the interval at which the plugin is queried again. Defaults to 30 minutes.
+	RequeueAfterSeconds int64                  `json:"requeueAfterSeconds,omitempty"`
+	Template            ApplicationSetTemplate `json:"template,omitempty"`
+
+	// Values contains key/value pairs which are passed directly as parameters to the template
+	Values map[string]string `json:"values,omitempty"`
+
+	// Filters restrict the generated parameter sets to those matching all of the filter expressions
+	Filters []GeneratorFilter `json:"filters,omitempty"`
+}
+
+// PluginInput is the input sent to a plugin.
+type PluginInput struct {
+	// Parameters are passed as-is to the plugin.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// SecretRef references a key of a Secret in the namespace of the ApplicationSet.
+type SecretRef struct {
+	SecretName string `json:"secretName"`
+	Key        string `json:"key"`
+}
+
 // ApplicationSetStatus defines the observed state of ApplicationSet
 type ApplicationSetStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the generation of the ApplicationSet spec that was last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the outcome of the last reconciliation, see ApplicationSetConditionType.
+	Conditions []ApplicationSetCondition `json:"conditions,omitempty"`
+	// Resources lists the Applications owned by the ApplicationSet, with their sync and health state.
+	Resources []ApplicationSetResourceStatus `json:"resources,omitempty"`
+	// ReconciledAt is the time of the last reconciliation, successful or not.
+	ReconciledAt *metav1.Time `json:"reconciledAt,omitempty"`
+	// Rollout is the progress of the RollingUpdate strategy, if the ApplicationSet uses it.
+	Rollout *ApplicationSetRolloutStatus `json:"rollout,omitempty"`
+	// GeneratorErrors lists the generators that failed in the last reconciliation, when IsolateGeneratorErrors is true.
+	GeneratorErrors []ApplicationSetGeneratorError `json:"generatorErrors,omitempty"`
+	// DriftedApplications lists the Applications changed manually, which are not updated as SelfHeal is false,
+	// sorted by name.
+	DriftedApplications []string `json:"driftedApplications,omitempty"`
+}
+
+// ApplicationSetGeneratorError is the error of a generator.
+type ApplicationSetGeneratorError struct {
+	// Generator is the index of the generator in spec.generators, starting at 0.
+	Generator int    `json:"generator"`
+	Message   string `json:"message"`
+}
+
+// ApplicationSetRolloutStatus is the progress of a RollingUpdate.
+type ApplicationSetRolloutStatus struct {
+	// CurrentStep is the step being rolled out, starting at 1, or 0 once every step is complete.
+	CurrentStep int `json:"currentStep"`
+	// Steps is the number of steps, including the implicit last step if some Applications are not selected by any
+	// step.
+	Steps int `json:"steps"`
+	// PendingApplications lists the Applications waiting to be created or updated, sorted by name.
+	PendingApplications []string `json:"pendingApplications,omitempty"`
+}
+
+// ApplicationSetConditionType is the type of an ApplicationSetCondition.
+type ApplicationSetConditionType string
+
+const (
+	// ApplicationSetConditionErrorOccurred is True if the last reconciliation failed, the Reason and Message
+	// describing the error.
+	ApplicationSetConditionErrorOccurred ApplicationSetConditionType = "ErrorOccurred"
+	// ApplicationSetConditionParametersGenerated is True if all the generators successfully generated their
+	// parameters.
+	ApplicationSetConditionParametersGenerated ApplicationSetConditionType = "ParametersGenerated"
+	// ApplicationSetConditionResourcesUpToDate is True if the Applications in the cluster match the generated ones.
+	ApplicationSetConditionResourcesUpToDate ApplicationSetConditionType = "ResourcesUpToDate"
+	// ApplicationSetConditionRolloutProgressing is True while the changes to the Applications are being rolled out.
+	ApplicationSetConditionRolloutProgressing ApplicationSetConditionType = "RolloutProgressing"
+	// ApplicationSetConditionDeletionBlocked is True while the DeletionSafeguards block the deletion of Applications.
+	ApplicationSetConditionDeletionBlocked ApplicationSetConditionType = "DeletionBlocked"
+)
+
+// ApplicationSetConditionStatus is the status of an ApplicationSetCondition: True, False or Unknown.
+type ApplicationSetConditionStatus string
+
+const (
+	ApplicationSetConditionStatusTrue    ApplicationSetConditionStatus = "True"
+	ApplicationSetConditionStatusFalse   ApplicationSetConditionStatus = "False"
+	ApplicationSetConditionStatusUnknown ApplicationSetConditionStatus = "Unknown"
+)
+
+// Reasons of the ApplicationSetConditions
+const (
+	ApplicationSetReasonApplicationSetUpToDate           = "ApplicationSetUpToDate"
+	ApplicationSetReasonParametersGenerated              = "ParametersGenerated"
+	ApplicationSetReasonApplicationParamsGenerationError = "ApplicationParamsGenerationError"
+	ApplicationSetReasonDuplicateApplicationNames        = "DuplicateApplicationNames"
+	ApplicationSetReasonUpdateApplicationError           = "UpdateApplicationError"
+	ApplicationSetReasonDeleteApplicationError           = "DeleteApplicationError"
+	ApplicationSetReasonRolloutComplete                  = "ApplicationSetRolloutComplete"
+	ApplicationSetReasonRolloutProgressing               = "ApplicationSetRolloutProgressing"
+	ApplicationSetReasonDeletionBlocked                  = "DeletionBlocked"
+	ApplicationSetReasonDeletionAllowed                  = "DeletionAllowed"
+	ApplicationSetReasonGeneratorError                   = "GeneratorError"
+	ApplicationSetReasonApplicationsDrifted              = "ApplicationsDrifted"
+)
+
+// ApplicationSetCondition describes one aspect of the state of the ApplicationSet.
+type ApplicationSetCondition struct {
+	// Type is the aspect described by the condition.
+	Type ApplicationSetConditionType `json:"type"`
+	// Status is True, False or Unknown.
+	Status ApplicationSetConditionStatus `json:"status"`
+	// Reason is a CamelCase identifier of the cause of the status.
+	Reason string `json:"reason"`
+	// Message is a human readable explanation of the status.
+	Message string `json:"message"`
+	// LastTransitionTime is the last time the status of the condition changed.
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ApplicationSetResourceStatus is the state of an Application owned by the ApplicationSet.
+type ApplicationSetResourceStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// SyncStatus is the sync status of the Application, e.g. Synced or OutOfSync.
+	SyncStatus string `json:"syncStatus,omitempty"`
+	// HealthStatus is the health status of the Application, e.g. Healthy or Degraded.
+	HealthStatus string `json:"healthStatus,omitempty"`
+}
+
+// SetCondition adds the condition to the status, replacing the condition of the same type. The LastTransitionTime
+// is only moved to now when the status of the condition changes.
+func (status *ApplicationSetStatus) SetCondition(condition ApplicationSetCondition, now metav1.Time) {
+	condition.LastTransitionTime = &now
+
+	for i, existing := range status.Conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.LastTransitionTime != nil {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		status.Conditions[i] = condition
+		return
+	}
+
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// GetCondition returns the condition of the given type, or nil if the status doesn't have one.
+func (status *ApplicationSetStatus) GetCondition(conditionType ApplicationSetConditionType) *ApplicationSetCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
 }
 
 // ApplicationSetList contains a list of ApplicationSet
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// document is the header shared by the Kubernetes resources, and the items of a List.
+type document struct {
+	Kind  string            `json:"kind"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// readDocuments returns the resources of the YAML or JSON file, in JSON, expanding the Lists, e.g. the output of
+// kubectl get -o yaml.
+func readDocuments(path string) ([][]byte, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var resources [][]byte
+	var kinds []string
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		content, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read %s: %v", path, err)
+		}
+
+		resource, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse %s: %v", path, err)
+		}
+		if string(resource) == "null" {
+			continue
+		}
+
+		var doc document
+		if err := json.Unmarshal(resource, &doc); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse %s: %v", path, err)
+		}
+		if doc.Kind != "List" {
+			resources = append(resources, resource)
+			kinds = append(kinds, doc.Kind)
+			continue
+		}
+		for _, item := range doc.Items {
+			var itemDoc document
+			if err := json.Unmarshal(item, &itemDoc); err != nil {
+				return nil, nil, fmt.Errorf("unable to parse %s: %v", path, err)
+			}
+			resources = append(resources, item)
+			kinds = append(kinds, itemDoc.Kind)
+		}
+	}
+	return resources, kinds, nil
+}
+
+// readResources unmarshals the resources of the file, which must all be of the kind, into the values returned by
+// newResource.
+func readResources(path string, kind string, newResource func() interface{}) error {
+	resources, kinds, err := readDocuments(path)
+	if err != nil {
+		return err
+	}
+
+	for i, resource := range resources {
+		if kinds[i] != kind {
+			return fmt.Errorf("%s: expected a %s, got a %q", path, kind, kinds[i])
+		}
+		if err := json.Unmarshal(resource, newResource()); err != nil {
+			return fmt.Errorf("%s: invalid %s: %v", path, kind, err)
+		}
+	}
+	return nil
+}
+
+// loadApplicationSets returns the ApplicationSets of the file, in namespace unless they set theirs.
+func loadApplicationSets(path string, namespace string) ([]argoprojiov1alpha1.ApplicationSet, error) {
+	var res []*argoprojiov1alpha1.ApplicationSet
+	err := readResources(path, "ApplicationSet", func() interface{} {
+		res = append(res, &argoprojiov1alpha1.ApplicationSet{})
+		return res[len(res)-1]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%s: no ApplicationSet found", path)
+	}
+
+	appSets := make([]argoprojiov1alpha1.ApplicationSet, len(res))
+	for i, appSet := range res {
+		if appSet.Namespace == "" {
+			appSet.Namespace = namespace
+		}
+		appSets[i] = *appSet
+	}
+	return appSets, nil
+}
+
+// loadApplications returns the Applications of the files, in namespace unless they set theirs.
+func loadApplications(paths []string, namespace string) ([]argov1alpha1.Application, error) {
+	var res []*argov1alpha1.Application
+	for _, path := range paths {
+		err := readResources(path, "Application", func() interface{} {
+			res = append(res, &argov1alpha1.Application{})
+			return res[len(res)-1]
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	apps := make([]argov1alpha1.Application, len(res))
+	for i, app := range res {
+		if app.Namespace == "" {
+			app.Namespace = namespace
+		}
+		apps[i] = *app
+	}
+	return apps, nil
+}
+
+// loadSecrets returns the Secrets of the files, in namespace unless they set theirs. Their stringData is merged into
+// their data, as the API server does.
+func loadSecrets(paths []string, namespace string) ([]*corev1.Secret, error) {
+	var res []*corev1.Secret
+	for _, path := range paths {
+		err := readResources(path, "Secret", func() interface{} {
+			res = append(res, &corev1.Secret{})
+			return res[len(res)-1]
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, secret := range res {
+		if secret.Namespace == "" {
+			secret.Namespace = namespace
+		}
+		if len(secret.StringData) > 0 && secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		for key, value := range secret.StringData {
+			secret.Data[key] = []byte(value)
+		}
+		secret.StringData = nil
+	}
+	return res, nil
+}
@@ -0,0 +1,477 @@
+// The appset command renders ApplicationSets offline, without a cluster: it runs their List, Clusters and Git
+// generators against files and local checkouts, e.g. to test ApplicationSets in pre-commit hooks and CI.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/controllers"
+	"github.com/argoproj-labs/applicationset/pkg/generators"
+	"github.com/argoproj-labs/applicationset/pkg/services"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const usage = `appset renders ApplicationSets offline, from files and local checkouts of their repositories.
+
+Usage:
+  appset generate [flags] APPLICATIONSET_FILE...
+  appset validate [flags] APPLICATIONSET_FILE...
+  appset diff [flags] --current APPLICATIONS_FILE APPLICATIONSET_FILE
+
+Commands:
+  generate  Print the Applications rendered by the ApplicationSets
+  validate  Check that the ApplicationSets render valid Applications
+  diff      Print the changes the ApplicationSet would make to the current Applications
+
+Only the List, Clusters and Git generators, and the Matrix and Merge generators combining them, are supported.
+Run 'appset COMMAND -h' for the flags of a command.
+`
+
+const (
+	// exitFailure is the exit code of invalid ApplicationSets and of diffs with changes
+	exitFailure = 1
+	// exitError is the exit code of the errors, e.g. unreadable files
+	exitError = 2
+)
+
+// offlineGenerators are the generators that can run without a cluster
+var offlineGenerators = []string{"List", "Clusters", "Git"}
+
+// stringsFlag is a flag that can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+type options struct {
+	repos          stringsFlag
+	clusterSecrets stringsFlag
+	current        stringsFlag
+	namespace      string
+	output         string
+	debug          bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of the arguments and returns its exit code.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+
+	command, args := args[0], args[1:]
+	opts := options{}
+	flags := flag.NewFlagSet("appset "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&opts.repos, "repo", "The local checkout of a repository, as URL=PATH, or PATH for the repositories not listed. Can be repeated. Defaults to the current directory for all repositories. The revisions of the generators are ignored.")
+	flags.Var(&opts.clusterSecrets, "cluster-secrets", "A YAML or JSON file of cluster Secrets, read by the Clusters generator. Can be repeated.")
+	flags.StringVar(&opts.namespace, "namespace", "argocd", "The namespace of the ApplicationSets that don't set theirs.")
+	flags.BoolVar(&opts.debug, "debug", false, "Print debug logs.")
+
+	switch command {
+	case "generate":
+		flags.StringVar(&opts.output, "output", "yaml", "The output format of the Applications: yaml or json.")
+	case "validate":
+	case "diff":
+		flags.Var(&opts.current, "current", "A YAML or JSON file of the current Applications of the ApplicationSet, e.g. the output of kubectl get applications -o yaml or of appset generate. Can be repeated.")
+		flags.StringVar(&opts.output, "output", "text", "The output format of the changes: text or json.")
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", command, usage)
+		return exitError
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitError
+	}
+	if command == "diff" && flags.NArg() != 1 {
+		fmt.Fprintf(stderr, "expected one ApplicationSet file\n\n%s", usage)
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintf(stderr, "expected ApplicationSet files\n\n%s", usage)
+		return exitError
+	}
+
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		// The generators log every rendering
+		log.SetLevel(log.WarnLevel)
+	}
+	log.SetOutput(stderr)
+
+	reconciler, err := newReconciler(opts)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	var code int
+	switch command {
+	case "generate":
+		code, err = generate(reconciler, flags.Args(), opts, stdout)
+	case "validate":
+		code, err = validate(reconciler, flags.Args(), opts, stdout)
+	case "diff":
+		code, err = diff(reconciler, flags.Arg(0), opts, stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return code
+}
+
+// newReconciler returns a reconciler rendering the ApplicationSets with the offline generators, reading the local
+// checkouts and the cluster Secrets of the options.
+func newReconciler(opts options) (*controllers.ApplicationSetReconciler, error) {
+	roots := map[string]string{}
+	for _, repo := range opts.repos {
+		repoURL, path := "", repo
+		if i := strings.Index(repo, "="); i >= 0 {
+			repoURL, path = repo[:i], repo[i+1:]
+		}
+		if _, found := roots[repoURL]; found {
+			return nil, fmt.Errorf("duplicate --repo %q", repo)
+		}
+		roots[repoURL] = path
+	}
+	if _, found := roots[""]; !found {
+		roots[""] = "."
+	}
+
+	secrets, err := loadSecrets(opts.clusterSecrets, opts.namespace)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]client.Object, len(secrets))
+	for i := range secrets {
+		objects[i] = secrets[i]
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	repos := services.NewLocalRepos(roots)
+
+	terminalGenerators := map[string]generators.Generator{
+		"List":                    generators.NewListGenerator(),
+		"Clusters":                generators.NewClusterGenerator(c),
+		"Git":                     generators.NewGitGenerator(repos),
+		"PullRequest":             unsupportedGenerator("PullRequest"),
+		"SCMProvider":             unsupportedGenerator("SCMProvider"),
+		"ClusterDecisionResource": unsupportedGenerator("ClusterDecisionResource"),
+		"Plugin":                  unsupportedGenerator("Plugin"),
+	}
+
+	topLevelGenerators := map[string]generators.Generator{
+		"Matrix": generators.NewMatrixGenerator(terminalGenerators),
+		"Merge":  generators.NewMergeGenerator(terminalGenerators),
+	}
+	for name, g := range terminalGenerators {
+		topLevelGenerators[name] = g
+	}
+
+	return &controllers.ApplicationSetReconciler{
+		Client:     c,
+		Generators: topLevelGenerators,
+		Renderer:   &utils.Render{},
+		Policy:     &utils.SyncPolicy{},
+	}, nil
+}
+
+// generate prints the Applications of the ApplicationSets of the files.
+func generate(reconciler *controllers.ApplicationSetReconciler, paths []string, opts options, stdout io.Writer) (int, error) {
+	if opts.output != "yaml" && opts.output != "json" {
+		return 0, fmt.Errorf("unknown output format %q, expected yaml or json", opts.output)
+	}
+
+	var apps []argov1alpha1.Application
+	for _, path := range paths {
+		appSets, err := loadApplicationSets(path, opts.namespace)
+		if err != nil {
+			return 0, err
+		}
+		for i := range appSets {
+			generated, generatorErrors, err := reconciler.GenerateApplications(&appSets[i])
+			if err != nil {
+				return 0, fmt.Errorf("ApplicationSet %s: %v", appSets[i].Name, err)
+			}
+			if len(generatorErrors) > 0 {
+				return 0, fmt.Errorf("ApplicationSet %s: %s", appSets[i].Name, strings.Join(generatorErrorMessages(generatorErrors), "; "))
+			}
+			apps = append(apps, generated...)
+		}
+	}
+
+	return 0, printApplications(apps, opts.output, stdout)
+}
+
+// validate checks the ApplicationSets of the files and the Applications they render, and prints the errors.
+func validate(reconciler *controllers.ApplicationSetReconciler, paths []string, opts options, stdout io.Writer) (int, error) {
+	code := 0
+	for _, path := range paths {
+		appSets, err := loadApplicationSets(path, opts.namespace)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := range appSets {
+			appSet := &appSets[i]
+			var errs []string
+			if appSet.Name == "" {
+				errs = append(errs, "metadata.name is required")
+			}
+			if len(appSet.Spec.Generators) == 0 {
+				errs = append(errs, "spec.generators is required")
+			}
+
+			apps, generatorErrors, err := reconciler.GenerateApplications(appSet)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			errs = append(errs, generatorErrorMessages(generatorErrors)...)
+			for j := range apps {
+				for _, err := range validateApplication(&apps[j]) {
+					errs = append(errs, fmt.Sprintf("Application %s: %s", apps[j].Name, err))
+				}
+			}
+
+			if len(errs) > 0 {
+				code = exitFailure
+				for _, err := range errs {
+					fmt.Fprintf(stdout, "%s: ApplicationSet %s: %s\n", path, appSet.Name, err)
+				}
+				continue
+			}
+			fmt.Fprintf(stdout, "%s: ApplicationSet %s is valid, rendering %d Applications\n", path, appSet.Name, len(apps))
+		}
+	}
+	return code, nil
+}
+
+// generatorErrorMessages returns the messages of the generators that failed, with IsolateGeneratorErrors.
+func generatorErrorMessages(generatorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError) []string {
+	res := make([]string, 0, len(generatorErrors))
+	for _, generatorError := range generatorErrors {
+		res = append(res, fmt.Sprintf("generator %d failed: %s", generatorError.Generator, generatorError.Message))
+	}
+	return res
+}
+
+// validateApplication returns the errors of the fields of the Application that Argo CD requires.
+func validateApplication(app *argov1alpha1.Application) []string {
+	var res []string
+	if app.Name == "" {
+		res = append(res, "metadata.name is required")
+	} else {
+		for _, err := range validation.IsDNS1123Subdomain(app.Name) {
+			res = append(res, fmt.Sprintf("invalid metadata.name: %s", err))
+		}
+	}
+	if app.Spec.Project == "" {
+		res = append(res, "spec.project is required")
+	}
+	if app.Spec.Destination.Server == "" && app.Spec.Destination.Name == "" {
+		res = append(res, "spec.destination.server or spec.destination.name is required")
+	}
+	if app.Spec.Destination.Server != "" && app.Spec.Destination.Name != "" {
+		res = append(res, "only one of spec.destination.server and spec.destination.name can be set")
+	}
+	if app.Spec.Source.RepoURL == "" {
+		res = append(res, "spec.source.repoURL is required")
+	}
+	return res
+}
+
+// diff prints the changes the ApplicationSet of the file would make to the current Applications, and returns
+// exitFailure if there are some.
+func diff(reconciler *controllers.ApplicationSetReconciler, path string, opts options, stdout io.Writer) (int, error) {
+	if opts.output != "text" && opts.output != "json" {
+		return 0, fmt.Errorf("unknown output format %q, expected text or json", opts.output)
+	}
+
+	appSets, err := loadApplicationSets(path, opts.namespace)
+	if err != nil {
+		return 0, err
+	}
+	if len(appSets) != 1 {
+		return 0, fmt.Errorf("%s: expected one ApplicationSet, got %d", path, len(appSets))
+	}
+	appSet := &appSets[0]
+
+	apps, err := loadApplications(opts.current, appSet.Namespace)
+	if err != nil {
+		return 0, err
+	}
+	// The Applications owned by other ApplicationSets, e.g. in the output of kubectl get applications, are ignored
+	var current []argov1alpha1.Application
+	for _, app := range apps {
+		owner := metav1.GetControllerOf(&app)
+		if owner == nil || (owner.Kind == "ApplicationSet" && owner.Name == appSet.Name) {
+			current = append(current, app)
+		}
+	}
+	desired, generatorErrors, err := reconciler.GenerateApplications(appSet)
+	if err != nil {
+		return 0, fmt.Errorf("ApplicationSet %s: %v", appSet.Name, err)
+	}
+	preview, err := reconciler.DiffApplications(appSet, current, desired, generatorErrors)
+	if err != nil {
+		return 0, fmt.Errorf("ApplicationSet %s: %v", appSet.Name, err)
+	}
+
+	if opts.output == "json" {
+		if err := printJSON(preview, stdout); err != nil {
+			return 0, err
+		}
+	} else {
+		printPreview(preview, stdout)
+	}
+
+	if len(preview.Create) > 0 || len(preview.Update) > 0 || len(preview.Delete) > 0 {
+		return exitFailure, nil
+	}
+	return 0, nil
+}
+
+// printPreview prints the created, updated and deleted Applications of the preview, one per line and sorted by name.
+func printPreview(preview *controllers.ApplicationSetPreview, stdout io.Writer) {
+	var lines []string
+	for _, app := range preview.Create {
+		lines = append(lines, fmt.Sprintf("+ %s", app.Name))
+	}
+	for _, update := range preview.Update {
+		lines = append(lines, fmt.Sprintf("~ %s: %s", update.Current.Name, strings.Join(update.ChangedFields, ", ")))
+	}
+	for _, app := range preview.Delete {
+		lines = append(lines, fmt.Sprintf("- %s", app.Name))
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i][2:] < lines[j][2:]
+	})
+
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+	for _, message := range generatorErrorMessages(preview.GeneratorErrors) {
+		fmt.Fprintf(stdout, "! %s, its Applications are left untouched\n", message)
+	}
+	fmt.Fprintf(stdout, "%d to create, %d to update, %d to delete, %d unchanged\n", len(preview.Create), len(preview.Update), len(preview.Delete), len(preview.Unchanged))
+}
+
+// printApplications prints the manifests of the Applications, as YAML documents or as a JSON List.
+func printApplications(apps []argov1alpha1.Application, output string, stdout io.Writer) error {
+	manifests := make([]map[string]interface{}, len(apps))
+	for i := range apps {
+		manifest, err := getManifest(&apps[i])
+		if err != nil {
+			return err
+		}
+		manifests[i] = manifest
+	}
+
+	if output == "json" {
+		return printJSON(map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": manifests}, stdout)
+	}
+
+	for i, manifest := range manifests {
+		content, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(stdout, "---")
+		}
+		if _, err := stdout.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getManifest returns the manifest of the Application, without its empty status.
+func getManifest(app *argov1alpha1.Application) (map[string]interface{}, error) {
+	app = app.DeepCopy()
+	app.APIVersion = argov1alpha1.SchemeGroupVersion.String()
+	app.Kind = "Application"
+
+	content, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]interface{}
+	if err := json.Unmarshal(content, &res); err != nil {
+		return nil, err
+	}
+
+	delete(res, "status")
+	if metadata, ok := res["metadata"].(map[string]interface{}); ok && metadata["creationTimestamp"] == nil {
+		delete(metadata, "creationTimestamp")
+	}
+	return res, nil
+}
+
+func printJSON(value interface{}, stdout io.Writer) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// unsupportedGenerator is registered in place of the generators that need a cluster or network access, to fail the
+// ApplicationSets using them instead of ignoring the generators.
+type unsupportedGenerator string
+
+var _ generators.Generator = unsupportedGenerator("")
+
+func (g unsupportedGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+	return nil, fmt.Errorf("the %s generator is not supported offline, only the %s generators are", string(g), strings.Join(offlineGenerators, ", "))
+}
+
+func (g unsupportedGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	return generators.NoRequeueAfter
+}
+
+func (g unsupportedGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &argoprojiov1alpha1.ApplicationSetTemplate{}
+}
+
+func (g unsupportedGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return nil
+}
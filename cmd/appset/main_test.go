@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const applicationSet = `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: guestbook
+spec:
+  generators:
+  - matrix:
+      generators:
+      - git:
+          repoURL: https://github.com/argoproj/argocd-example-apps.git
+          revision: HEAD
+          files:
+          - path: "cluster-config/*/config.json"
+      - clusters:
+          selector:
+            matchLabels:
+              env: prod
+  template:
+    metadata:
+      name: 'guestbook-{{env}}'
+    spec:
+      project: '{{project}}'
+      source:
+        repoURL: https://github.com/argoproj/argocd-example-apps.git
+        targetRevision: HEAD
+        path: guestbook
+      destination:
+        server: '{{server}}'
+        namespace: guestbook
+`
+
+const clusterSecrets = `apiVersion: v1
+kind: Secret
+metadata:
+  name: dev
+  labels:
+    argocd.argoproj.io/secret-type: cluster
+    env: dev
+stringData:
+  name: dev
+  server: https://dev.example.com
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: prod
+  labels:
+    argocd.argoproj.io/secret-type: cluster
+    env: prod
+stringData:
+  name: prod
+  server: https://prod.example.com
+`
+
+const currentApplications = `apiVersion: v1
+kind: List
+items:
+- apiVersion: argoproj.io/v1alpha1
+  kind: Application
+  metadata:
+    name: guestbook-dev
+  spec:
+    project: default
+    source:
+      repoURL: https://github.com/argoproj/argocd-example-apps.git
+      targetRevision: HEAD
+      path: guestbook
+    destination:
+      server: https://dev.example.com
+      namespace: guestbook
+- apiVersion: argoproj.io/v1alpha1
+  kind: Application
+  metadata:
+    name: guestbook-staging
+  spec:
+    project: default
+- apiVersion: argoproj.io/v1alpha1
+  kind: Application
+  metadata:
+    name: other
+    ownerReferences:
+    - apiVersion: argoproj.io/v1alpha1
+      kind: ApplicationSet
+      name: other
+      controller: true
+  spec:
+    project: default
+`
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appset")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for path, content := range map[string]string{
+		"repo/cluster-config/dev/config.json":  `{"env": "dev", "project": "default"}`,
+		"repo/cluster-config/prod/config.json": `{"env": "prod", "project": "production"}`,
+		"appset.yaml":                          applicationSet,
+		"invalid-appset.yaml":                  `{"kind": "ApplicationSet", "metadata": {"name": "invalid"}, "spec": {"generators": [{"list": {"elements": [{"cluster": "Invalid_Name"}]}}], "template": {"metadata": {"name": "{{cluster}}"}}}}`,
+		"pull-request-appset.yaml":             `{"kind": "ApplicationSet", "metadata": {"name": "pr"}, "spec": {"generators": [{"pullRequest": {}}]}}`,
+		"clusters.yaml":                        clusterSecrets,
+		"current.yaml":                         currentApplications,
+	} {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755)
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644)
+		assert.Nil(t, err)
+	}
+
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	repo := "--repo=https://github.com/argoproj/argocd-example-apps=" + path("repo")
+
+	for _, c := range []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:         "generate",
+			args:         []string{"generate", repo, "--cluster-secrets", path("clusters.yaml"), path("appset.yaml")},
+			expectedCode: 0,
+			expectedOutput: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  finalizers:
+  - resources-finalizer.argocd.argoproj.io
+  name: guestbook-dev
+  namespace: argocd
+spec:
+  destination:
+    namespace: guestbook
+    server: https://prod.example.com
+  project: default
+  source:
+    path: guestbook
+    repoURL: https://github.com/argoproj/argocd-example-apps.git
+    targetRevision: HEAD
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  finalizers:
+  - resources-finalizer.argocd.argoproj.io
+  name: guestbook-prod
+  namespace: argocd
+spec:
+  destination:
+    namespace: guestbook
+    server: https://prod.example.com
+  project: production
+  source:
+    path: guestbook
+    repoURL: https://github.com/argoproj/argocd-example-apps.git
+    targetRevision: HEAD
+`,
+		},
+		{
+			name:           "generate without the cluster Secrets",
+			args:           []string{"generate", repo, path("appset.yaml")},
+			expectedCode:   0,
+			expectedOutput: "",
+		},
+		{
+			name:         "validate",
+			args:         []string{"validate", repo, "--cluster-secrets", path("clusters.yaml"), path("appset.yaml"), path("invalid-appset.yaml")},
+			expectedCode: exitFailure,
+			expectedOutput: path("appset.yaml") + ": ApplicationSet guestbook is valid, rendering 2 Applications\n" +
+				path("invalid-appset.yaml") + ": ApplicationSet invalid: Application Invalid_Name: invalid metadata.name: a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')\n" +
+				path("invalid-appset.yaml") + ": ApplicationSet invalid: Application Invalid_Name: spec.project is required\n" +
+				path("invalid-appset.yaml") + ": ApplicationSet invalid: Application Invalid_Name: spec.destination.server or spec.destination.name is required\n" +
+				path("invalid-appset.yaml") + ": ApplicationSet invalid: Application Invalid_Name: spec.source.repoURL is required\n",
+		},
+		{
+			name:         "validate an unsupported generator",
+			args:         []string{"validate", path("pull-request-appset.yaml")},
+			expectedCode: exitFailure,
+			expectedOutput: path("pull-request-appset.yaml") + ": ApplicationSet pr: the PullRequest generator is not supported offline, " +
+				"only the List, Clusters, Git generators are\n",
+		},
+		{
+			name:         "diff",
+			args:         []string{"diff", repo, "--cluster-secrets", path("clusters.yaml"), "--current", path("current.yaml"), path("appset.yaml")},
+			expectedCode: exitFailure,
+			expectedOutput: `~ guestbook-dev: spec.destination.server
++ guestbook-prod
+- guestbook-staging
+1 to create, 1 to update, 1 to delete, 0 unchanged
+`,
+		},
+		{
+			name:         "diff without the cluster Secrets",
+			args:         []string{"diff", repo, "--current", path("current.yaml"), path("appset.yaml")},
+			expectedCode: exitFailure,
+			expectedOutput: `- guestbook-dev
+- guestbook-staging
+0 to create, 0 to update, 2 to delete, 0 unchanged
+`,
+		},
+		{
+			name:         "missing file",
+			args:         []string{"generate", path("missing.yaml")},
+			expectedCode: exitError,
+		},
+		{
+			name:         "wrong kind",
+			args:         []string{"generate", path("clusters.yaml")},
+			expectedCode: exitError,
+		},
+		{
+			name:         "unknown command",
+			args:         []string{"apply", path("appset.yaml")},
+			expectedCode: exitError,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(c.args, &stdout, &stderr)
+			assert.Equal(t, c.expectedCode, code, stderr.String())
+			assert.Equal(t, c.expectedOutput, stdout.String())
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appset")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	appSetPath := filepath.Join(dir, "appset.yaml")
+	err = ioutil.WriteFile(appSetPath, []byte(`apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: guestbook
+  namespace: applicationsets
+spec:
+  generators:
+  - list:
+      elements:
+      - cluster: dev
+  template:
+    metadata:
+      name: 'guestbook-{{cluster}}'
+    spec:
+      project: default
+`), 0644)
+	assert.Nil(t, err)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"generate", "--output", "json", appSetPath}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	var list struct {
+		Kind  string `json:"kind"`
+		Items []struct {
+			Kind     string                 `json:"kind"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"items"`
+	}
+	err = json.Unmarshal(stdout.Bytes(), &list)
+	assert.Nil(t, err)
+	assert.Equal(t, "List", list.Kind)
+	if assert.Len(t, list.Items, 1) {
+		assert.Equal(t, "Application", list.Items[0].Kind)
+		assert.Equal(t, map[string]interface{}{
+			"name":       "guestbook-dev",
+			"namespace":  "applicationsets",
+			"finalizers": []interface{}{"resources-finalizer.argocd.argoproj.io"},
+		}, list.Items[0].Metadata)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/argoproj-labs/applicationset
 go 1.14
 
 require (
+	github.com/antonmedv/expr v1.8.9
 	github.com/argoproj/argo-cd v1.8.1
 	github.com/argoproj/gitops-engine v0.2.1
 	github.com/argoproj/pkg v0.2.0
@@ -19,6 +20,7 @@ require (
 	k8s.io/client-go v11.0.1-0.20190816222228-6d55c1b1f1ca+incompatible
 	k8s.io/kubernetes v1.19.2
 	sigs.k8s.io/controller-runtime v0.7.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
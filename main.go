@@ -20,7 +20,9 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -29,9 +31,11 @@ import (
 	"github.com/argoproj-labs/applicationset/pkg/generators"
 	"github.com/argoproj-labs/applicationset/pkg/services"
 	"github.com/argoproj-labs/applicationset/pkg/utils"
+	"github.com/argoproj-labs/applicationset/pkg/webhook"
 
 	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -39,6 +43,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,20 +64,32 @@ func init() {
 func main() {
 	var metricsAddr string
 	var probeBindAddr string
+	var webhookAddr string
+	var webhookAllowUnauthenticated bool
+	var previewAddr string
 	var enableLeaderElection bool
 	var namespace string
 	var argocdRepoServer string
+	var repoCacheMaxIdle time.Duration
+	var repoCacheMaxRepos int
+	var repoCacheMaxSizeMB int64
 	var policy string
 	var debugLog bool
 	var dryRun bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeBindAddr, "probe-addr", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&webhookAddr, "webhook-addr", ":7000", "The address the Git webhook endpoint binds to. Set to an empty string to disable it.")
+	flag.BoolVar(&webhookAllowUnauthenticated, "webhook-allow-unauthenticated", false, "Accept the Git webhooks of the providers without a webhook secret in the argocd-secret Secret. By default they are refused.")
+	flag.StringVar(&previewAddr, "preview-addr", "", "The address the ApplicationSet preview endpoint binds to. It is disabled by default: the previews are generated with the credentials of the controller.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&namespace, "namespace", "", "Argo CD repo namespace (default: argocd)")
 	flag.StringVar(&argocdRepoServer, "argocd-repo-server", "argocd-repo-server:8081", "Argo CD repo server address")
-	flag.StringVar(&policy, "policy", "sync", "Modify how application is synced between the generator and the cluster. Default is 'sync' (create & update & delete), options: 'create-only', 'create-update' (no deletion)")
+	flag.DurationVar(&repoCacheMaxIdle, "repo-cache-max-idle", time.Hour, "The duration after which the unused local clones of the Git repositories are deleted. Set to 0 to keep them.")
+	flag.IntVar(&repoCacheMaxRepos, "repo-cache-max-repos", 100, "The number of local clones of the Git repositories above which the least recently used are deleted. Set to 0 for no limit.")
+	flag.Int64Var(&repoCacheMaxSizeMB, "repo-cache-max-size-mb", 0, "The size on disk, in MiB, of the local clones of the Git repositories above which the least recently used are deleted. Set to 0 for no limit.")
+	flag.StringVar(&policy, "policy", "sync", "Modify how application is synced between the generator and the cluster. Default is 'sync' (create & update & delete), options: 'create-only', 'create-update' (no deletion), 'create-delete' (no update). ApplicationSets can restrict it further with spec.syncPolicy.applicationsSync")
 	flag.BoolVar(&debugLog, "debug", false, "Print debug logs")
 	flag.BoolVar(&dryRun, "dry-run", false, "Enable dry run mode")
 	flag.Parse()
@@ -81,7 +98,7 @@ func main() {
 
 	policyObj, exists := utils.Policies[policy]
 	if !exists {
-		setupLog.Info("Policy value can be: sync, create-only, create-update")
+		setupLog.Info("Policy value can be: sync, create-only, create-update, create-delete")
 		os.Exit(1)
 	}
 
@@ -124,27 +141,76 @@ func main() {
 	}
 
 	k8s := kubernetes.NewForConfigOrDie(mgr.GetConfig())
+	dynClient := dynamic.NewForConfigOrDie(mgr.GetConfig())
+	ctx := ctrl.SetupSignalHandler()
+
+	repos := services.NewArgoCDService(context.Background(), k8s, namespace, argocdRepoServer, repoCacheMaxIdle, repoCacheMaxRepos, repoCacheMaxSizeMB*1024*1024)
+
+	terminalGenerators := map[string]generators.Generator{
+		"List":                    generators.NewListGenerator(),
+		"Clusters":                generators.NewClusterGenerator(mgr.GetClient()),
+		"Git":                     generators.NewGitGenerator(repos),
+		"PullRequest":             generators.NewPullRequestGenerator(mgr.GetClient()),
+		"SCMProvider":             generators.NewSCMProviderGenerator(mgr.GetClient(), repos),
+		"ClusterDecisionResource": generators.NewDuckTypeGenerator(mgr.GetClient(), dynClient, namespace),
+		"Plugin":                  generators.NewPluginGenerator(mgr.GetClient(), namespace),
+	}
+
+	topLevelGenerators := map[string]generators.Generator{
+		"List":                    terminalGenerators["List"],
+		"Clusters":                terminalGenerators["Clusters"],
+		"Git":                     terminalGenerators["Git"],
+		"PullRequest":             terminalGenerators["PullRequest"],
+		"SCMProvider":             terminalGenerators["SCMProvider"],
+		"ClusterDecisionResource": terminalGenerators["ClusterDecisionResource"],
+		"Plugin":                  terminalGenerators["Plugin"],
+		"Matrix":                  generators.NewMatrixGenerator(terminalGenerators),
+		"Merge":                   generators.NewMergeGenerator(terminalGenerators),
+	}
+
+	var webhookHandler *webhook.WebhookHandler
+	if webhookAddr != "" {
+		webhookHandler = webhook.NewWebhookHandler(mgr.GetClient(), namespace, webhookAllowUnauthenticated)
+		// The webhook server runs with the controller, on the leader, which consumes the events it sends
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return webhookHandler.Serve(ctx, webhookAddr)
+		})); err != nil {
+			setupLog.Error(err, "unable to add the webhook server")
+			os.Exit(1)
+		}
+	}
 
-	if err = (&controllers.ApplicationSetReconciler{
-		Generators: map[string]generators.Generator{
-			"List":     generators.NewListGenerator(),
-			"Clusters": generators.NewClusterGenerator(mgr.GetClient()),
-			"Git":      generators.NewGitGenerator(services.NewArgoCDService(context.Background(), k8s, namespace, argocdRepoServer)),
-		},
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("ApplicationSet"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("applicationset-controller"),
-		Renderer: &utils.Render{},
-		Policy:   policyObj,
-	}).SetupWithManager(mgr); err != nil {
+	reconciler := &controllers.ApplicationSetReconciler{
+		Generators:      topLevelGenerators,
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("ApplicationSet"),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("applicationset-controller"),
+		Renderer:        &utils.Render{},
+		Policy:          policyObj,
+		DuckTypeWatcher: controllers.NewDuckTypeWatcher(ctx, mgr.GetClient(), dynClient, namespace),
+		WebhookHandler:  webhookHandler,
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ApplicationSet")
 		os.Exit(1)
 	}
+
+	if previewAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(controllers.PreviewPath, controllers.NewPreviewHandler(reconciler, namespace))
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			setupLog.Info("Serving ApplicationSet previews", "addr", previewAddr)
+			return utils.ListenAndServe(ctx, previewAddr, mux)
+		})); err != nil {
+			setupLog.Error(err, "unable to add the preview server")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("Starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -27,14 +27,18 @@ import (
 
 	"github.com/argoproj-labs/applicationset/pkg/generators"
 	"github.com/argoproj-labs/applicationset/pkg/utils"
+	"github.com/argoproj-labs/applicationset/pkg/webhook"
 	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/apis/core"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	log "github.com/sirupsen/logrus"
@@ -56,6 +60,12 @@ type ApplicationSetReconciler struct {
 	Generators map[string]generators.Generator
 	utils.Policy
 	utils.Renderer
+	// DuckTypeWatcher requeues ApplicationSets when the resources read by their clusterDecisionResource generators
+	// change. It is optional.
+	DuckTypeWatcher *DuckTypeWatcher
+	// WebhookHandler requeues ApplicationSets when the repositories read by their Git, pull request and SCM provider
+	// generators send a webhook. It is optional.
+	WebhookHandler *webhook.WebhookHandler
 }
 
 // +kubebuilder:rbac:groups=argoproj.io,resources=applicationsets,verbs=get;list;watch;create;update;patch;delete
@@ -76,11 +86,27 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	// Log a warning if there are unrecognized generators
 	checkInvalidGenerators(&applicationSetInfo)
 
+	if r.DuckTypeWatcher != nil {
+		if err := r.DuckTypeWatcher.WatchApplicationSet(ctx, &applicationSetInfo); err != nil {
+			log.WithField("applicationset", req.NamespacedName).WithError(err).Warn("unable to watch cluster decision resources")
+		}
+	}
+
 	// desiredApplications is the main list of all expected Applications from all generators in this appset.
-	desiredApplications, err := r.generateApplications(applicationSetInfo)
-	if err != nil {
-		return ctrl.Result{}, err
+	var desiredApplications []argov1alpha1.Application
+	var generatorErrors map[int]error
+	var err error
+	if applicationSetInfo.Spec.IsolateGeneratorErrors {
+		desiredApplications, generatorErrors = r.generateApplicationsIsolated(applicationSetInfo)
+	} else {
+		desiredApplications, err = r.generateApplications(applicationSetInfo)
+		if err != nil {
+			r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError, err)
+			return ctrl.Result{}, err
+		}
 	}
+	applicationSetInfo.Status.GeneratorErrors = getGeneratorErrorStatuses(generatorErrors)
+
 	if hasDuplicates, name := hasDuplicateNames(desiredApplications); hasDuplicates {
 		// The reconciler presumes that any errors that are returned are a signal
 		// that the resource should attempt to be reconciled again (causing
@@ -94,28 +120,99 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// successfully reconciled (which is true... it was reconciled to an
 		// error condition).
 		log.Errorf("ApplicationSet %s contains applications with duplicate name: %s", applicationSetInfo.Name, name)
+		r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonDuplicateApplicationNames,
+			fmt.Errorf("ApplicationSet %s contains applications with duplicate name: %s", applicationSetInfo.Name, name))
 		return ctrl.Result{}, nil
 	}
 
-	if r.Policy.Update() {
-		err = r.createOrUpdateInCluster(ctx, applicationSetInfo, desiredApplications)
+	// keptApplications are the Applications that must not be deleted: the desired ones, and those that may belong to
+	// a failed generator, which are not updated either
+	keptApplications := desiredApplications
+	if len(generatorErrors) > 0 {
+		for _, generatorError := range applicationSetInfo.Status.GeneratorErrors {
+			r.Recorder.Eventf(&applicationSetInfo, core.EventTypeWarning, argoprojiov1alpha1.ApplicationSetReasonGeneratorError,
+				"generator %d failed: %s", generatorError.Generator, generatorError.Message)
+		}
+
+		current, err := r.getCurrentApplications(ctx, applicationSetInfo)
+		if err != nil {
+			r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError, err)
+			return ctrl.Result{}, err
+		}
+		protected := getProtectedApplications(current, applicationSetInfo.Spec.Generators, applicationSetInfo.Status.GeneratorErrors)
+		desiredApplications = excludeApplications(desiredApplications, protected)
+		keptApplications = append(append([]argov1alpha1.Application{}, desiredApplications...), protected...)
+	}
+
+	policy := r.getPolicy(&applicationSetInfo)
+
+	// The Applications changed manually are reverted by the update, unless SelfHeal is false
+	drifted, err := r.getDriftedApplications(ctx, applicationSetInfo, desiredApplications)
+	if err != nil {
+		r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError, err)
+		return ctrl.Result{}, err
+	}
+	applicationSetInfo.Status.DriftedApplications = nil
+	if policy.Update() && isSelfHeal(&applicationSetInfo) {
+		for _, app := range drifted {
+			log.WithFields(log.Fields{"app": app.Name, "appSet": applicationSetInfo.Name}).Info("reverting the manual changes to the Application")
+		}
+	} else if len(drifted) > 0 {
+		desiredApplications = excludeApplications(desiredApplications, drifted)
+		applicationSetInfo.Status.DriftedApplications = getApplicationNames(drifted)
+		r.Recorder.Eventf(&applicationSetInfo, core.EventTypeWarning, argoprojiov1alpha1.ApplicationSetReasonApplicationsDrifted,
+			"Applications changed manually are not updated: %s", strings.Join(applicationSetInfo.Status.DriftedApplications, ", "))
+	}
+
+	if policy.Update() {
+		if isRollingUpdate(&applicationSetInfo) {
+			var rollout *argoprojiov1alpha1.ApplicationSetRolloutStatus
+			rollout, err = r.rollOutInCluster(ctx, applicationSetInfo, desiredApplications)
+			if err == nil {
+				applicationSetInfo.Status.Rollout = rollout
+			}
+		} else {
+			applicationSetInfo.Status.Rollout = nil
+			err = r.createOrUpdateInCluster(ctx, applicationSetInfo, desiredApplications)
+		}
 		if err != nil {
+			r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError, err)
 			return ctrl.Result{}, err
 		}
 	} else {
 		err = r.createInCluster(ctx, applicationSetInfo, desiredApplications)
 		if err != nil {
+			r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError, err)
 			return ctrl.Result{}, err
 		}
 	}
 
-	if r.Policy.Delete() {
-		err = r.deleteInCluster(ctx, applicationSetInfo, desiredApplications)
+	if policy.Delete() {
+		allowed, err := r.checkDeletionSafeguards(ctx, &applicationSetInfo, keptApplications)
 		if err != nil {
+			r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonDeleteApplicationError, err)
 			return ctrl.Result{}, err
 		}
+		if allowed {
+			err = r.deleteInCluster(ctx, applicationSetInfo, keptApplications)
+			if err != nil {
+				r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonDeleteApplicationError, err)
+				return ctrl.Result{}, err
+			}
+			if err := r.clearDeletionAcknowledgement(ctx, &applicationSetInfo); err != nil {
+				log.WithField("appSet", applicationSetInfo.Name).WithError(err).Warn("unable to remove the deletion acknowledgement annotation")
+			}
+		}
 	}
 
+	if len(generatorErrors) > 0 {
+		err := generatorsError(applicationSetInfo.Status.GeneratorErrors)
+		r.updateStatus(ctx, &applicationSetInfo, argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError, err)
+		return ctrl.Result{}, err
+	}
+
+	r.updateStatus(ctx, &applicationSetInfo, "", nil)
+
 	requeueAfter := r.getMinRequeueAfter(&applicationSetInfo)
 	log.WithField("requeueAfter", requeueAfter).Info("end reconcile")
 
@@ -220,21 +317,24 @@ func hasDuplicateNames(applications []argov1alpha1.Application) (bool, string) {
 }
 
 func (r *ApplicationSetReconciler) GetRelevantGenerators(requestedGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []generators.Generator {
-	var res []generators.Generator
+	return generators.GetRelevantGenerators(requestedGenerator, r.Generators)
+}
 
-	v := reflect.Indirect(reflect.ValueOf(requestedGenerator))
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		if !field.CanInterface() {
-			continue
-		}
+// getPolicy returns the policy of the ApplicationSet, set by spec.syncPolicy.applicationsSync, restricted to the
+// policy of the controller. An unknown policy is logged and treated as create-only.
+func (r *ApplicationSetReconciler) getPolicy(applicationSetInfo *argoprojiov1alpha1.ApplicationSet) utils.Policy {
+	syncPolicy := applicationSetInfo.Spec.SyncPolicy
+	if syncPolicy == nil || syncPolicy.ApplicationsSync == nil {
+		return r.Policy
+	}
 
-		if !reflect.ValueOf(field.Interface()).IsNil() {
-			res = append(res, r.Generators[v.Type().Field(i).Name])
-		}
+	policy, exists := utils.Policies[string(*syncPolicy.ApplicationsSync)]
+	if !exists {
+		log.WithField("appSet", applicationSetInfo.Name).Warnf("unknown applicationsSync policy %q, using create-only", *syncPolicy.ApplicationsSync)
+		policy = &utils.CreateOnlyPolicy{}
 	}
 
-	return res
+	return utils.RestrictPolicy(policy, r.Policy)
 }
 
 func (r *ApplicationSetReconciler) getMinRequeueAfter(applicationSetInfo *argoprojiov1alpha1.ApplicationSet) time.Duration {
@@ -283,49 +383,72 @@ func (r *ApplicationSetReconciler) generateApplications(applicationSetInfo argop
 
 	var firstError error
 	for _, requestedGenerator := range applicationSetInfo.Spec.Generators {
-		generators := r.GetRelevantGenerators(&requestedGenerator)
-		for _, g := range generators {
+		apps, err := r.generateApplicationsForGenerator(applicationSetInfo, &requestedGenerator)
+		if err != nil && firstError == nil {
+			firstError = err
+		}
+		res = append(res, apps...)
+	}
+	return res, firstError
+}
 
-			// we call mergeGeneratorTemplate first because GenerateParams might be more costly so we want to fail fast if there is an error
-			mergedTemplate, err := mergeGeneratorTemplate(g, &requestedGenerator, applicationSetInfo.Spec.Template)
-			if err != nil {
-				log.WithError(err).WithField("generator", g).
-					Error("error generating params")
-				if firstError == nil {
-					firstError = err
-				}
-				continue
+// generateApplicationsForGenerator returns the Applications generated by one of the generators of the ApplicationSet.
+func (r *ApplicationSetReconciler) generateApplicationsForGenerator(applicationSetInfo argoprojiov1alpha1.ApplicationSet, requestedGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]argov1alpha1.Application, error) {
+	res := []argov1alpha1.Application{}
+
+	var firstError error
+	relevantGenerators := r.GetRelevantGenerators(requestedGenerator)
+	for _, g := range relevantGenerators {
+
+		// we call mergeGeneratorTemplate first because GenerateParams might be more costly so we want to fail fast if there is an error
+		mergedTemplate, err := mergeGeneratorTemplate(g, requestedGenerator, applicationSetInfo.Spec.Template)
+		if err != nil {
+			log.WithError(err).WithField("generator", g).
+				Error("error generating params")
+			if firstError == nil {
+				firstError = err
 			}
+			continue
+		}
+
+		params, err := g.GenerateParams(requestedGenerator, &applicationSetInfo)
+		if err != nil {
+			log.WithError(err).WithField("generator", g).
+				Error("error generating params")
+			if firstError == nil {
+				firstError = err
+			}
+			continue
+		}
+
+		params, err = generators.FilterParams(g.GetFilters(requestedGenerator), params)
+		if err != nil {
+			log.WithError(err).WithField("generator", g).
+				Error("error filtering params")
+			if firstError == nil {
+				firstError = err
+			}
+			continue
+		}
+
+		tmplApplication := getTempApplication(mergedTemplate)
 
-			params, err := g.GenerateParams(&requestedGenerator)
+		for _, p := range params {
+			app, err := r.Renderer.RenderTemplateParams(tmplApplication, p, applicationSetInfo.Spec.GoTemplate)
 			if err != nil {
-				log.WithError(err).WithField("generator", g).
-					Error("error generating params")
+				log.WithError(err).WithField("params", params).WithField("generator", g).
+					Error("error generating application from params")
 				if firstError == nil {
 					firstError = err
 				}
 				continue
 			}
+			res = append(res, *app)
+		}
 
-			tmplApplication := getTempApplication(mergedTemplate)
-
-			for _, p := range params {
-				app, err := r.Renderer.RenderTemplateParams(tmplApplication, p)
-				if err != nil {
-					log.WithError(err).WithField("params", params).WithField("generator", g).
-						Error("error generating application from params")
-					if firstError == nil {
-						firstError = err
-					}
-					continue
-				}
-				res = append(res, *app)
-			}
-
-			log.WithField("generator", g).Infof("generated %d applications", len(res))
-			log.WithField("generator", g).Debugf("apps from generator: %+v", res)
+		log.WithField("generator", g).Infof("generated %d applications", len(res))
+		log.WithField("generator", g).Debugf("apps from generator: %+v", res)
 
-		}
 	}
 	return res, firstError
 }
@@ -349,22 +472,47 @@ func (r *ApplicationSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&argoprojiov1alpha1.ApplicationSet{}).
-		Owns(&argov1alpha1.Application{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&argoprojiov1alpha1.ApplicationSet{}, builder.WithPredicates(ignoreStatusOnlyUpdates)).
+		Owns(&argov1alpha1.Application{}, builder.WithPredicates(ignoreOwnedApplicationStatusUpdates)).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
 			&clusterSecretEventHandler{
 				Client: mgr.GetClient(),
 				Log:    log.WithField("type", "createSecretEventHandler"),
-			}).
-		// TODO: also watch Applications and respond on changes if we own them.
-		Complete(r)
+			})
+
+	if r.DuckTypeWatcher != nil {
+		b = b.Watches(
+			&source.Channel{Source: r.DuckTypeWatcher.Events()},
+			&handler.EnqueueRequestForObject{})
+	}
+
+	if r.WebhookHandler != nil {
+		b = b.Watches(
+			&source.Channel{Source: r.WebhookHandler.Events()},
+			&handler.EnqueueRequestForObject{})
+	}
+
+	return b.Complete(r)
+}
+
+// ignoreStatusOnlyUpdates filters out the updates of an ApplicationSet that only change its status, such as the
+// ones made at the end of every reconciliation, which would otherwise trigger another reconciliation.
+var ignoreStatusOnlyUpdates = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return true
+		}
+		return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+			!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+			!reflect.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+	},
 }
 
 // createOrUpdateInCluster will create / update application resources in the cluster.
 // For new application it will call create
-// For application that need to update it will call update
+// For application that need to update it will call update, keeping the fields managed outside of the ApplicationSet
 // The function also adds owner reference to all applications, and uses it for delete them.
 func (r *ApplicationSetReconciler) createOrUpdateInCluster(ctx context.Context, applicationSet argoprojiov1alpha1.ApplicationSet, desiredApplications []argov1alpha1.Application) error {
 
@@ -374,9 +522,21 @@ func (r *ApplicationSetReconciler) createOrUpdateInCluster(ctx context.Context,
 		appLog := log.WithFields(log.Fields{"app": app.Name, "appSet": applicationSet.Name})
 		app.Namespace = applicationSet.Namespace
 
-		found := app
+		// The live Application is read into found, merging it with the fields already set, so found must not share
+		// the maps of app, and only holds the fields that aren't set by the merge, e.g. the finalizers of a new
+		// Application
+		found := argov1alpha1.Application{
+			TypeMeta: app.TypeMeta,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       app.Name,
+				Namespace:  app.Namespace,
+				Finalizers: append([]string(nil), app.Finalizers...),
+			},
+		}
 		action, err := utils.CreateOrUpdate(ctx, r.Client, &found, func() error {
-			found.Spec = app.Spec
+			if err := mergeDesiredApplication(&applicationSet, &app, &found); err != nil {
+				return err
+			}
 			return controllerutil.SetControllerReference(&applicationSet, &found, r.Scheme)
 		})
 
@@ -394,6 +554,30 @@ func (r *ApplicationSetReconciler) createOrUpdateInCluster(ctx context.Context,
 	return firstError
 }
 
+// mergeDesiredApplication sets the fields of the live Application managed by the ApplicationSet to their desired
+// value, keeping the fields managed outside of the ApplicationSet.
+func mergeDesiredApplication(applicationSet *argoprojiov1alpha1.ApplicationSet, desired, live *argov1alpha1.Application) error {
+	merged, err := utils.MergeLiveApplication(applicationSet, desired, live)
+	if err != nil {
+		return err
+	}
+	live.Spec = merged.Spec
+	live.Annotations = merged.Annotations
+	live.Labels = merged.Labels
+
+	if !isSelfHeal(applicationSet) {
+		hash, err := utils.HashApplication(applicationSet, live)
+		if err != nil {
+			return err
+		}
+		if live.Annotations == nil {
+			live.Annotations = map[string]string{}
+		}
+		live.Annotations[argoprojiov1alpha1.AnnotationApplicationHash] = hash
+	}
+	return nil
+}
+
 // createInCluster will filter from the desiredApplications only the application that needs to be created
 // Then it will call createOrUpdateInCluster to do the actual create
 func (r *ApplicationSetReconciler) createInCluster(ctx context.Context, applicationSet argoprojiov1alpha1.ApplicationSet, desiredApplications []argov1alpha1.Application) error {
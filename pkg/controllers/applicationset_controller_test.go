@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/argoproj-labs/applicationset/pkg/generators"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
 	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/sirupsen/logrus"
 	logtest "github.com/sirupsen/logrus/hooks/test"
@@ -34,10 +35,16 @@ func (g *generatorMock) GetTemplate(appSetGenerator *argoprojiov1alpha1.Applicat
 	return args.Get(0).(*argoprojiov1alpha1.ApplicationSetTemplate)
 }
 
-func (g *generatorMock) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error) {
+func (g *generatorMock) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
 	args := g.Called(appSetGenerator)
 
-	return args.Get(0).([]map[string]string), args.Error(1)
+	return args.Get(0).([]argoprojiov1alpha1.GeneratorFilter)
+}
+
+func (g *generatorMock) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+	args := g.Called(appSetGenerator, applicationSetInfo)
+
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
 type rendererMock struct {
@@ -50,8 +57,8 @@ func (g *generatorMock) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.Appl
 	return args.Get(0).(time.Duration)
 }
 
-func (r *rendererMock) RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]string) (*argov1alpha1.Application, error) {
-	args := r.Called(tmpl, params)
+func (r *rendererMock) RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]interface{}, useGoTemplate bool) (*argov1alpha1.Application, error) {
+	args := r.Called(tmpl, params, useGoTemplate)
 
 	if args.Error(1) != nil {
 		return nil, args.Error(1)
@@ -73,7 +80,7 @@ func TestExtractApplications(t *testing.T) {
 
 	for _, c := range []struct {
 		name                string
-		params              []map[string]string
+		params              []map[string]interface{}
 		template            argoprojiov1alpha1.ApplicationSetTemplate
 		generateParamsError error
 		rendererError       error
@@ -81,7 +88,7 @@ func TestExtractApplications(t *testing.T) {
 	}{
 		{
 			name:   "Generate two applications",
-			params: []map[string]string{{"name": "app1"}, {"name": "app2"}},
+			params: []map[string]interface{}{{"name": "app1"}, {"name": "app2"}},
 			template: argoprojiov1alpha1.ApplicationSetTemplate{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "name",
@@ -98,7 +105,7 @@ func TestExtractApplications(t *testing.T) {
 		},
 		{
 			name:   "Handles error from the render",
-			params: []map[string]string{{"name": "app1"}, {"name": "app2"}},
+			params: []map[string]interface{}{{"name": "app1"}, {"name": "app2"}},
 			template: argoprojiov1alpha1.ApplicationSetTemplate{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "name",
@@ -125,12 +132,15 @@ func TestExtractApplications(t *testing.T) {
 				List: &argoprojiov1alpha1.ListGenerator{},
 			}
 
-			generatorMock.On("GenerateParams", &generator).
+			generatorMock.On("GenerateParams", &generator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
 				Return(cc.params, cc.generateParamsError)
 
 			generatorMock.On("GetTemplate", &generator).
 				Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
 
+			generatorMock.On("GetFilters", &generator).
+				Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
 			rendererMock := rendererMock{}
 
 			expectedApps := []argov1alpha1.Application{}
@@ -139,10 +149,10 @@ func TestExtractApplications(t *testing.T) {
 				for _, p := range cc.params {
 
 					if cc.rendererError != nil {
-						rendererMock.On("RenderTemplateParams", getTempApplication(cc.template), p).
+						rendererMock.On("RenderTemplateParams", getTempApplication(cc.template), p, false).
 							Return(nil, cc.rendererError)
 					} else {
-						rendererMock.On("RenderTemplateParams", getTempApplication(cc.template), p).
+						rendererMock.On("RenderTemplateParams", getTempApplication(cc.template), p, false).
 							Return(&app, nil)
 						expectedApps = append(expectedApps, app)
 					}
@@ -196,7 +206,7 @@ func TestMergeTemplateApplications(t *testing.T) {
 
 	for _, c := range []struct {
 		name             string
-		params           []map[string]string
+		params           []map[string]interface{}
 		template         argoprojiov1alpha1.ApplicationSetTemplate
 		overrideTemplate argoprojiov1alpha1.ApplicationSetTemplate
 		expectedMerged   argoprojiov1alpha1.ApplicationSetTemplate
@@ -204,7 +214,7 @@ func TestMergeTemplateApplications(t *testing.T) {
 	}{
 		{
 			name:   "Generate app",
-			params: []map[string]string{{"name": "app1"}},
+			params: []map[string]interface{}{{"name": "app1"}},
 			template: argoprojiov1alpha1.ApplicationSetTemplate{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "name",
@@ -249,15 +259,18 @@ func TestMergeTemplateApplications(t *testing.T) {
 				List: &argoprojiov1alpha1.ListGenerator{},
 			}
 
-			generatorMock.On("GenerateParams", &generator).
+			generatorMock.On("GenerateParams", &generator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
 				Return(cc.params, nil)
 
 			generatorMock.On("GetTemplate", &generator).
 				Return(&cc.overrideTemplate)
 
+			generatorMock.On("GetFilters", &generator).
+				Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
 			rendererMock := rendererMock{}
 
-			rendererMock.On("RenderTemplateParams", getTempApplication(cc.expectedMerged), cc.params[0]).
+			rendererMock.On("RenderTemplateParams", getTempApplication(cc.expectedMerged), cc.params[0], false).
 				Return(&cc.expectedApps[0], nil)
 
 			r := ApplicationSetReconciler{
@@ -446,6 +459,73 @@ func TestCreateOrUpdateInCluster(t *testing.T) {
 				},
 			},
 		},
+		{
+			appSet: argoprojiov1alpha1.ApplicationSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: argoprojiov1alpha1.ApplicationSetSpec{
+					PreservedFields: &argoprojiov1alpha1.ApplicationPreservedFields{
+						Annotations: []string{"image-updater"},
+					},
+					IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+						{JSONPointers: []string{"/spec/source/targetRevision"}},
+					},
+				},
+			},
+			existsApps: []argov1alpha1.Application{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Application",
+						APIVersion: "argoproj.io/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "app1",
+						Namespace:       "namespace",
+						ResourceVersion: "2",
+						Annotations:     map[string]string{"image-updater": "v2", "notified.notifications.argoproj.io": "{}", "removed": "true"},
+						Labels:          map[string]string{"team": "old"},
+					},
+					Spec: argov1alpha1.ApplicationSpec{
+						Project: "test",
+						Source:  argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: "manual"},
+					},
+				},
+			},
+			apps: []argov1alpha1.Application{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "app1",
+						Annotations: map[string]string{"image-updater": "v1", "generated": "true"},
+						Labels:      map[string]string{"team": "new"},
+					},
+					Spec: argov1alpha1.ApplicationSpec{
+						Project: "project",
+						Source:  argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: "HEAD"},
+					},
+				},
+			},
+			expected: []argov1alpha1.Application{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Application",
+						APIVersion: "argoproj.io/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "app1",
+						Namespace:       "namespace",
+						ResourceVersion: "3",
+						Annotations:     map[string]string{"image-updater": "v2", "notified.notifications.argoproj.io": "{}", "generated": "true"},
+						Labels:          map[string]string{"team": "new"},
+					},
+					Spec: argov1alpha1.ApplicationSpec{
+						Project: "project",
+						Source:  argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: "manual"},
+					},
+				},
+			},
+		},
 	} {
 		initObjs := []client.Object{&c.appSet}
 		for _, a := range c.existsApps {
@@ -1302,3 +1382,41 @@ func TestHasDuplicateNames(t *testing.T) {
 		assert.Equal(t, c.duplicateName, name)
 	}
 }
+
+func TestGetPolicy(t *testing.T) {
+	policy := func(p argoprojiov1alpha1.ApplicationsSyncPolicy) *argoprojiov1alpha1.ApplicationsSyncPolicy {
+		return &p
+	}
+
+	for _, c := range []struct {
+		name             string
+		controllerPolicy string
+		applicationsSync *argoprojiov1alpha1.ApplicationsSyncPolicy
+		expectedUpdate   bool
+		expectedDelete   bool
+	}{
+		{"controller policy by default", "sync", nil, true, true},
+		{"applicationSet restricts the controller policy", "sync", policy(argoprojiov1alpha1.ApplicationsSyncPolicyCreateUpdate), true, false},
+		{"create-delete", "sync", policy(argoprojiov1alpha1.ApplicationsSyncPolicyCreateDelete), false, true},
+		{"applicationSet can't exceed the controller policy", "create-only", policy(argoprojiov1alpha1.ApplicationsSyncPolicySync), false, false},
+		{"policies are intersected", "create-update", policy(argoprojiov1alpha1.ApplicationsSyncPolicyCreateDelete), false, false},
+		{"unknown policy is create-only", "sync", policy("update-only"), false, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := ApplicationSetReconciler{
+				Policy: utils.Policies[c.controllerPolicy],
+			}
+			appSet := argoprojiov1alpha1.ApplicationSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+			}
+			if c.applicationsSync != nil {
+				appSet.Spec.SyncPolicy = &argoprojiov1alpha1.ApplicationSetSyncPolicy{ApplicationsSync: c.applicationsSync}
+			}
+
+			got := r.getPolicy(&appSet)
+
+			assert.Equal(t, c.expectedUpdate, got.Update())
+			assert.Equal(t, c.expectedDelete, got.Delete())
+		})
+	}
+}
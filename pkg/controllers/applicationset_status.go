@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// updateStatus records the outcome of a reconciliation in the status of the ApplicationSet: its conditions, the
+// Applications it owns and the reconciliation time. reason identifies the step that failed with reconcileErr, and
+// is ignored on success (nil reconcileErr). Failures to update the status are only logged, so that they never hide
+// the outcome of the reconciliation itself.
+func (r *ApplicationSetReconciler) updateStatus(ctx context.Context, applicationSet *argoprojiov1alpha1.ApplicationSet, reason string, reconcileErr error) {
+	appSetLog := log.WithField("appSet", applicationSet.Name)
+
+	now := metav1.Now()
+	status := applicationSet.Status.DeepCopy()
+	for _, condition := range getStatusConditions(reason, reconcileErr) {
+		status.SetCondition(condition, now)
+	}
+	if reconcileErr == nil && status.Rollout != nil && status.Rollout.CurrentStep != 0 {
+		status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{
+			Type:    argoprojiov1alpha1.ApplicationSetConditionRolloutProgressing,
+			Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			Reason:  argoprojiov1alpha1.ApplicationSetReasonRolloutProgressing,
+			Message: fmt.Sprintf("Rolling out step %d of %d", status.Rollout.CurrentStep, status.Rollout.Steps),
+		}, now)
+	}
+	if reconcileErr == nil && len(status.DriftedApplications) > 0 {
+		status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{
+			Type:   argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate,
+			Status: argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			Reason: argoprojiov1alpha1.ApplicationSetReasonApplicationsDrifted,
+			Message: fmt.Sprintf("%d Applications were changed manually and are not updated: %s",
+				len(status.DriftedApplications), strings.Join(status.DriftedApplications, ", ")),
+		}, now)
+	}
+	status.ObservedGeneration = applicationSet.Generation
+	status.ReconciledAt = &now
+
+	current, err := r.getCurrentApplications(ctx, *applicationSet)
+	if err != nil {
+		appSetLog.WithError(err).Warn("unable to list the Applications of the ApplicationSet, keeping their previous status")
+	} else {
+		status.Resources = getResourceStatuses(current)
+	}
+
+	applicationSet.Status = *status
+	if err := r.Client.Status().Update(ctx, applicationSet); err != nil {
+		appSetLog.WithError(err).Error("unable to update the ApplicationSet status")
+	}
+}
+
+// getStatusConditions returns the conditions describing the outcome of a reconciliation, see updateStatus.
+func getStatusConditions(reason string, reconcileErr error) []argoprojiov1alpha1.ApplicationSetCondition {
+	if reconcileErr == nil {
+		return []argoprojiov1alpha1.ApplicationSetCondition{
+			{
+				Type:    argoprojiov1alpha1.ApplicationSetConditionErrorOccurred,
+				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+				Reason:  argoprojiov1alpha1.ApplicationSetReasonApplicationSetUpToDate,
+				Message: "All applications have been generated successfully",
+			},
+			{
+				Type:    argoprojiov1alpha1.ApplicationSetConditionParametersGenerated,
+				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+				Reason:  argoprojiov1alpha1.ApplicationSetReasonParametersGenerated,
+				Message: "Successfully generated parameters for all Applications",
+			},
+			{
+				Type:    argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate,
+				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+				Reason:  argoprojiov1alpha1.ApplicationSetReasonApplicationSetUpToDate,
+				Message: "ApplicationSet up to date",
+			},
+			{
+				Type:    argoprojiov1alpha1.ApplicationSetConditionRolloutProgressing,
+				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+				Reason:  argoprojiov1alpha1.ApplicationSetReasonRolloutComplete,
+				Message: "All Applications have been updated",
+			},
+		}
+	}
+
+	message := reconcileErr.Error()
+
+	paramsGenerated := argoprojiov1alpha1.ApplicationSetCondition{
+		Type:    argoprojiov1alpha1.ApplicationSetConditionParametersGenerated,
+		Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+		Reason:  argoprojiov1alpha1.ApplicationSetReasonParametersGenerated,
+		Message: "Successfully generated parameters for all Applications",
+	}
+	if reason == argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError {
+		paramsGenerated.Status = argoprojiov1alpha1.ApplicationSetConditionStatusFalse
+		paramsGenerated.Reason = reason
+		paramsGenerated.Message = message
+	}
+
+	return []argoprojiov1alpha1.ApplicationSetCondition{
+		{
+			Type:    argoprojiov1alpha1.ApplicationSetConditionErrorOccurred,
+			Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			Reason:  reason,
+			Message: message,
+		},
+		paramsGenerated,
+		{
+			Type:    argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate,
+			Status:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
+
+// getResourceStatuses returns the status of the Applications, sorted by name.
+func getResourceStatuses(applications []argov1alpha1.Application) []argoprojiov1alpha1.ApplicationSetResourceStatus {
+	res := make([]argoprojiov1alpha1.ApplicationSetResourceStatus, 0, len(applications))
+	for _, app := range applications {
+		res = append(res, argoprojiov1alpha1.ApplicationSetResourceStatus{
+			Name:         app.Name,
+			Namespace:    app.Namespace,
+			SyncStatus:   string(app.Status.Sync.Status),
+			HealthStatus: string(app.Status.Health.Status),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
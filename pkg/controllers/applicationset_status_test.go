@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestGetStatusConditions(t *testing.T) {
+	conditionsByType := func(conditions []argoprojiov1alpha1.ApplicationSetCondition) map[argoprojiov1alpha1.ApplicationSetConditionType]argoprojiov1alpha1.ApplicationSetCondition {
+		res := map[argoprojiov1alpha1.ApplicationSetConditionType]argoprojiov1alpha1.ApplicationSetCondition{}
+		for _, c := range conditions {
+			res[c.Type] = c
+		}
+		return res
+	}
+
+	for _, c := range []struct {
+		name             string
+		reason           string
+		err              error
+		errorOccurred    argoprojiov1alpha1.ApplicationSetConditionStatus
+		paramsGenerated  argoprojiov1alpha1.ApplicationSetConditionStatus
+		resourcesUpdated argoprojiov1alpha1.ApplicationSetConditionStatus
+		expectedReason   string
+	}{
+		{
+			name:             "success",
+			errorOccurred:    argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			paramsGenerated:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			resourcesUpdated: argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			expectedReason:   argoprojiov1alpha1.ApplicationSetReasonApplicationSetUpToDate,
+		},
+		{
+			name:             "parameters generation error",
+			reason:           argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError,
+			err:              errors.New("git fetch error"),
+			errorOccurred:    argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			paramsGenerated:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			resourcesUpdated: argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			expectedReason:   argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError,
+		},
+		{
+			name:             "application update error",
+			reason:           argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError,
+			err:              errors.New("update error"),
+			errorOccurred:    argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			paramsGenerated:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			resourcesUpdated: argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			expectedReason:   argoprojiov1alpha1.ApplicationSetReasonUpdateApplicationError,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			conditions := conditionsByType(getStatusConditions(c.reason, c.err))
+
+			assert.Equal(t, c.errorOccurred, conditions[argoprojiov1alpha1.ApplicationSetConditionErrorOccurred].Status)
+			assert.Equal(t, c.paramsGenerated, conditions[argoprojiov1alpha1.ApplicationSetConditionParametersGenerated].Status)
+			assert.Equal(t, c.resourcesUpdated, conditions[argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate].Status)
+			assert.Equal(t, c.expectedReason, conditions[argoprojiov1alpha1.ApplicationSetConditionErrorOccurred].Reason)
+			if c.err != nil {
+				assert.Equal(t, c.err.Error(), conditions[argoprojiov1alpha1.ApplicationSetConditionErrorOccurred].Message)
+			}
+		})
+	}
+}
+
+func TestSetConditionKeepsTransitionTime(t *testing.T) {
+	before := metav1.NewTime(time.Now().Add(-time.Minute))
+	now := metav1.Now()
+
+	status := argoprojiov1alpha1.ApplicationSetStatus{
+		Conditions: []argoprojiov1alpha1.ApplicationSetCondition{
+			{Type: argoprojiov1alpha1.ApplicationSetConditionErrorOccurred, Status: argoprojiov1alpha1.ApplicationSetConditionStatusFalse, LastTransitionTime: &before},
+			{Type: argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate, Status: argoprojiov1alpha1.ApplicationSetConditionStatusTrue, LastTransitionTime: &before},
+		},
+	}
+
+	status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{Type: argoprojiov1alpha1.ApplicationSetConditionErrorOccurred, Status: argoprojiov1alpha1.ApplicationSetConditionStatusFalse, Message: "new message"}, now)
+	status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{Type: argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate, Status: argoprojiov1alpha1.ApplicationSetConditionStatusFalse}, now)
+	status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{Type: argoprojiov1alpha1.ApplicationSetConditionParametersGenerated, Status: argoprojiov1alpha1.ApplicationSetConditionStatusTrue}, now)
+
+	assert.Len(t, status.Conditions, 3)
+
+	unchanged := status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionErrorOccurred)
+	assert.Equal(t, before, *unchanged.LastTransitionTime)
+	assert.Equal(t, "new message", unchanged.Message)
+
+	changed := status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate)
+	assert.Equal(t, now, *changed.LastTransitionTime)
+
+	added := status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionParametersGenerated)
+	assert.Equal(t, now, *added.LastTransitionTime)
+}
+
+func TestGetResourceStatuses(t *testing.T) {
+	apps := []argov1alpha1.Application{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "argocd"},
+			Status: argov1alpha1.ApplicationStatus{
+				Sync:   argov1alpha1.SyncStatus{Status: argov1alpha1.SyncStatusCodeOutOfSync},
+				Health: argov1alpha1.HealthStatus{Status: "Degraded"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "argocd"},
+			Status: argov1alpha1.ApplicationStatus{
+				Sync:   argov1alpha1.SyncStatus{Status: argov1alpha1.SyncStatusCodeSynced},
+				Health: argov1alpha1.HealthStatus{Status: "Healthy"},
+			},
+		},
+	}
+
+	assert.Equal(t, []argoprojiov1alpha1.ApplicationSetResourceStatus{
+		{Name: "a", Namespace: "argocd", SyncStatus: "Synced", HealthStatus: "Healthy"},
+		{Name: "b", Namespace: "argocd", SyncStatus: "OutOfSync", HealthStatus: "Degraded"},
+	}, getResourceStatuses(apps))
+}
+
+func TestUpdateStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "name",
+			Namespace:  "namespace",
+			Generation: 3,
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appSet).Build()
+
+	r := ApplicationSetReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	r.updateStatus(context.TODO(), &appSet, argoprojiov1alpha1.ApplicationSetReasonDuplicateApplicationNames, errors.New("duplicate name: app"))
+
+	var got argoprojiov1alpha1.ApplicationSet
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "name", Namespace: "namespace"}, &got)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int64(3), got.Status.ObservedGeneration)
+	assert.NotNil(t, got.Status.ReconciledAt)
+	errorOccurred := got.Status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionErrorOccurred)
+	if assert.NotNil(t, errorOccurred) {
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetConditionStatusTrue, errorOccurred.Status)
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetReasonDuplicateApplicationNames, errorOccurred.Reason)
+		assert.Equal(t, "duplicate name: app", errorOccurred.Message)
+	}
+}
+
+func TestUpdateStatusRolloutProgressing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appSet).Build()
+
+	r := ApplicationSetReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	appSet.Status.Rollout = &argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 2, Steps: 3, PendingApplications: []string{"prod"}}
+	r.updateStatus(context.TODO(), &appSet, "", nil)
+
+	var got argoprojiov1alpha1.ApplicationSet
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "name", Namespace: "namespace"}, &got)
+	assert.Nil(t, err)
+
+	assert.Equal(t, appSet.Status.Rollout, got.Status.Rollout)
+	progressing := got.Status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionRolloutProgressing)
+	if assert.NotNil(t, progressing) {
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetConditionStatusTrue, progressing.Status)
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetReasonRolloutProgressing, progressing.Reason)
+		assert.Equal(t, "Rolling out step 2 of 3", progressing.Message)
+	}
+}
+
+func TestUpdateStatusDriftedApplications(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appSet).Build()
+
+	r := ApplicationSetReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	appSet.Status.DriftedApplications = []string{"dev", "prod"}
+	r.updateStatus(context.TODO(), &appSet, "", nil)
+
+	var got argoprojiov1alpha1.ApplicationSet
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "name", Namespace: "namespace"}, &got)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"dev", "prod"}, got.Status.DriftedApplications)
+	upToDate := got.Status.GetCondition(argoprojiov1alpha1.ApplicationSetConditionResourcesUpToDate)
+	if assert.NotNil(t, upToDate) {
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetConditionStatusFalse, upToDate.Status)
+		assert.Equal(t, argoprojiov1alpha1.ApplicationSetReasonApplicationsDrifted, upToDate.Reason)
+		assert.Equal(t, "2 Applications were changed manually and are not updated: dev, prod", upToDate.Message)
+	}
+}
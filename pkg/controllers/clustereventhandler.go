@@ -5,6 +5,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -28,7 +30,9 @@ func (h *clusterSecretEventHandler) Create(e event.CreateEvent, q workqueue.Rate
 }
 
 func (h *clusterSecretEventHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	h.queueRelatedAppGenerators(q, e.ObjectNew)
+	// Both versions are checked, so that the ApplicationSets of a cluster that no longer matches their selectors
+	// are requeued too
+	h.queueRelatedAppGenerators(q, e.ObjectOld, e.ObjectNew)
 }
 
 func (h *clusterSecretEventHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
@@ -39,15 +43,22 @@ func (h *clusterSecretEventHandler) Generic(e event.GenericEvent, q workqueue.Ra
 	h.queueRelatedAppGenerators(q, e.Object)
 }
 
-func (h *clusterSecretEventHandler) queueRelatedAppGenerators(q workqueue.RateLimitingInterface, object client.Object) {
-	// Check for label, lookup all ApplicationSets that might match the cluster, queue them all
-	if object.GetLabels()[generators.ArgoCDSecretTypeLabel] != generators.ArgoCDSecretTypeCluster {
+// queueRelatedAppGenerators queues the ApplicationSets with a generator reading the given versions of a Secret, if it
+// is a cluster Secret.
+func (h *clusterSecretEventHandler) queueRelatedAppGenerators(q workqueue.RateLimitingInterface, objects ...client.Object) {
+	var clusterLabels []labels.Set
+	for _, object := range objects {
+		if object != nil && object.GetLabels()[generators.ArgoCDSecretTypeLabel] == generators.ArgoCDSecretTypeCluster {
+			clusterLabels = append(clusterLabels, labels.Set(object.GetLabels()))
+		}
+	}
+	if len(clusterLabels) == 0 {
 		return
 	}
 
 	h.Log.WithFields(log.Fields{
-		"namespace": object.GetNamespace(),
-		"name":      object.GetName(),
+		"namespace": objects[0].GetNamespace(),
+		"name":      objects[0].GetName(),
 	}).Info("processing event for cluster secret")
 
 	appSetList := &argoprojiov1alpha1.ApplicationSetList{}
@@ -60,15 +71,56 @@ func (h *clusterSecretEventHandler) queueRelatedAppGenerators(q workqueue.RateLi
 	for _, appSet := range appSetList.Items {
 		foundClusterGenerator := false
 		for _, generator := range appSet.Spec.Generators {
-			if generator.Clusters != nil {
+			if generatorReadsCluster(generator.Clusters, generator.ClusterDecisionResource, clusterLabels) {
+				foundClusterGenerator = true
+				break
+			}
+			if generator.Matrix != nil && nestedReadsCluster(generator.Matrix.Generators, clusterLabels) {
+				foundClusterGenerator = true
+				break
+			}
+			if generator.Merge != nil && nestedReadsCluster(generator.Merge.Generators, clusterLabels) {
 				foundClusterGenerator = true
 				break
 			}
 		}
 		if foundClusterGenerator {
-			// TODO: only queue the AppGenerator if the labels match this cluster
 			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: appSet.Namespace, Name: appSet.Name}}
 			q.Add(req)
 		}
 	}
 }
+
+// nestedReadsCluster returns true if any of the child generators of a matrix or merge generator reads the cluster
+// Secret.
+func nestedReadsCluster(nestedGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator, clusterLabels []labels.Set) bool {
+	for _, generator := range nestedGenerators {
+		if generatorReadsCluster(generator.Clusters, generator.ClusterDecisionResource, clusterLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// generatorReadsCluster returns true if a cluster generator selects the cluster Secret with any of the given labels,
+// or for any cluster decision resource generator, which reads the cluster Secrets by name. A cluster generator with
+// an invalid selector is assumed to read it, its error is reported by the reconciliation.
+func generatorReadsCluster(clusters *argoprojiov1alpha1.ClusterGenerator, duckType *argoprojiov1alpha1.DuckTypeGenerator, clusterLabels []labels.Set) bool {
+	if duckType != nil {
+		return true
+	}
+	if clusters == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&clusters.Selector)
+	if err != nil {
+		return true
+	}
+	for _, set := range clusterLabels {
+		if selector.Matches(set) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/generators"
+)
+
+func TestClusterSecretEventHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := func(name string, generator argoprojiov1alpha1.ApplicationSetGenerator) client.Object {
+		return &argoprojiov1alpha1.ApplicationSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "argocd"},
+			Spec: argoprojiov1alpha1.ApplicationSetSpec{
+				Generators: []argoprojiov1alpha1.ApplicationSetGenerator{generator},
+			},
+		}
+	}
+	selector := func(matchLabels map[string]string) *argoprojiov1alpha1.ClusterGenerator {
+		return &argoprojiov1alpha1.ClusterGenerator{Selector: metav1.LabelSelector{MatchLabels: matchLabels}}
+	}
+
+	appSets := []client.Object{
+		appSet("all-clusters", argoprojiov1alpha1.ApplicationSetGenerator{Clusters: selector(nil)}),
+		appSet("staging", argoprojiov1alpha1.ApplicationSetGenerator{Clusters: selector(map[string]string{"env": "staging"})}),
+		appSet("production", argoprojiov1alpha1.ApplicationSetGenerator{Clusters: selector(map[string]string{"env": "production"})}),
+		appSet("matrix-production", argoprojiov1alpha1.ApplicationSetGenerator{
+			Matrix: &argoprojiov1alpha1.MatrixGenerator{
+				Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+					{List: &argoprojiov1alpha1.ListGenerator{}},
+					{Clusters: selector(map[string]string{"env": "production"})},
+				},
+			},
+		}),
+		appSet("merge-staging", argoprojiov1alpha1.ApplicationSetGenerator{
+			Merge: &argoprojiov1alpha1.MergeGenerator{
+				Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+					{Clusters: selector(map[string]string{"env": "staging"})},
+				},
+			},
+		}),
+		appSet("duck-type", argoprojiov1alpha1.ApplicationSetGenerator{
+			ClusterDecisionResource: &argoprojiov1alpha1.DuckTypeGenerator{},
+		}),
+		appSet("list", argoprojiov1alpha1.ApplicationSetGenerator{List: &argoprojiov1alpha1.ListGenerator{}}),
+	}
+
+	secret := func(labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "argocd", Labels: labels}}
+	}
+	cluster := func(env string) *corev1.Secret {
+		return secret(map[string]string{generators.ArgoCDSecretTypeLabel: generators.ArgoCDSecretTypeCluster, "env": env})
+	}
+
+	for _, c := range []struct {
+		name     string
+		send     func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface)
+		expected []string
+	}{
+		{
+			name: "create",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Create(event.CreateEvent{Object: cluster("staging")}, q)
+			},
+			expected: []string{"all-clusters", "staging", "merge-staging", "duck-type"},
+		},
+		{
+			name: "delete",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Delete(event.DeleteEvent{Object: cluster("production")}, q)
+			},
+			expected: []string{"all-clusters", "production", "matrix-production", "duck-type"},
+		},
+		{
+			name: "label changed",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Update(event.UpdateEvent{ObjectOld: cluster("staging"), ObjectNew: cluster("production")}, q)
+			},
+			expected: []string{"all-clusters", "staging", "merge-staging", "production", "matrix-production", "duck-type"},
+		},
+		{
+			name: "label unchanged",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Update(event.UpdateEvent{ObjectOld: cluster("staging"), ObjectNew: cluster("staging")}, q)
+			},
+			expected: []string{"all-clusters", "staging", "merge-staging", "duck-type"},
+		},
+		{
+			name: "label matching no selector",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Update(event.UpdateEvent{ObjectOld: cluster("dev"), ObjectNew: cluster("test")}, q)
+			},
+			expected: []string{"all-clusters", "duck-type"},
+		},
+		{
+			name: "no longer a cluster secret",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Update(event.UpdateEvent{ObjectOld: cluster("production"), ObjectNew: secret(map[string]string{"env": "production"})}, q)
+			},
+			expected: []string{"all-clusters", "production", "matrix-production", "duck-type"},
+		},
+		{
+			name: "not a cluster secret",
+			send: func(h *clusterSecretEventHandler, q workqueue.RateLimitingInterface) {
+				h.Create(event.CreateEvent{Object: secret(map[string]string{"env": "production"})}, q)
+			},
+			expected: []string{},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			h := &clusterSecretEventHandler{
+				Log:    log.WithField("test", c.name),
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(appSets...).Build(),
+			}
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+
+			c.send(h, q)
+
+			got := []string{}
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				got = append(got, item.(ctrl.Request).Name)
+				q.Done(item)
+			}
+			assert.ElementsMatch(t, c.expected, got)
+		})
+	}
+}
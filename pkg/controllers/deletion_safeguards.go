@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kubernetes/pkg/apis/core"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// checkDeletionSafeguards returns whether the deletion of the Applications that are no longer generated is allowed by
+// the DeletionSafeguards of the ApplicationSet, or was acknowledged. A blocked deletion is reported with a warning
+// event and the DeletionBlocked condition.
+func (r *ApplicationSetReconciler) checkDeletionSafeguards(ctx context.Context, applicationSet *argoprojiov1alpha1.ApplicationSet, desiredApplications []argov1alpha1.Application) (bool, error) {
+	syncPolicy := applicationSet.Spec.SyncPolicy
+	if syncPolicy == nil || syncPolicy.DeletionSafeguards == nil {
+		return true, nil
+	}
+
+	current, err := r.getCurrentApplications(ctx, *applicationSet)
+	if err != nil {
+		return false, err
+	}
+
+	message, err := getDeletionBlockedMessage(syncPolicy.DeletionSafeguards, desiredApplications, current)
+	if err != nil {
+		return false, err
+	}
+
+	if message == "" {
+		applicationSet.Status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{
+			Type:    argoprojiov1alpha1.ApplicationSetConditionDeletionBlocked,
+			Status:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			Reason:  argoprojiov1alpha1.ApplicationSetReasonDeletionAllowed,
+			Message: "The deletion of Applications is within the deletion safeguards",
+		}, metav1.Now())
+		return true, nil
+	}
+
+	if applicationSet.Annotations[argoprojiov1alpha1.AnnotationAcknowledgeDeletion] == "true" {
+		log.WithField("appSet", applicationSet.Name).Infof("deletion acknowledged: %s", message)
+		applicationSet.Status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{
+			Type:    argoprojiov1alpha1.ApplicationSetConditionDeletionBlocked,
+			Status:  argoprojiov1alpha1.ApplicationSetConditionStatusFalse,
+			Reason:  argoprojiov1alpha1.ApplicationSetReasonDeletionAllowed,
+			Message: fmt.Sprintf("Deletion acknowledged: %s", message),
+		}, metav1.Now())
+		return true, nil
+	}
+
+	message = fmt.Sprintf("%s; annotate the ApplicationSet with %s=true to proceed", message, argoprojiov1alpha1.AnnotationAcknowledgeDeletion)
+	log.WithField("appSet", applicationSet.Name).Warnf("deletion blocked: %s", message)
+	r.Recorder.Event(applicationSet, core.EventTypeWarning, argoprojiov1alpha1.ApplicationSetReasonDeletionBlocked, message)
+	applicationSet.Status.SetCondition(argoprojiov1alpha1.ApplicationSetCondition{
+		Type:    argoprojiov1alpha1.ApplicationSetConditionDeletionBlocked,
+		Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+		Reason:  argoprojiov1alpha1.ApplicationSetReasonDeletionBlocked,
+		Message: message,
+	}, metav1.Now())
+
+	return false, nil
+}
+
+// clearDeletionAcknowledgement removes the AnnotationAcknowledgeDeletion annotation, so that an acknowledgement only
+// allows a single deletion.
+func (r *ApplicationSetReconciler) clearDeletionAcknowledgement(ctx context.Context, applicationSet *argoprojiov1alpha1.ApplicationSet) error {
+	if _, found := applicationSet.Annotations[argoprojiov1alpha1.AnnotationAcknowledgeDeletion]; !found {
+		return nil
+	}
+
+	// The patch response overwrites the status being computed by the reconciliation
+	status := applicationSet.Status.DeepCopy()
+	patch := client.MergeFrom(applicationSet.DeepCopy())
+	delete(applicationSet.Annotations, argoprojiov1alpha1.AnnotationAcknowledgeDeletion)
+	err := r.Client.Patch(ctx, applicationSet, patch)
+	applicationSet.Status = *status
+	return err
+}
+
+// getDeletionBlockedMessage returns why the safeguards block the deletion of the current Applications that are not
+// desired, or "" if they don't.
+func getDeletionBlockedMessage(safeguards *argoprojiov1alpha1.ApplicationSetDeletionSafeguards, desiredApplications, currentApplications []argov1alpha1.Application) (string, error) {
+	desired := make(map[string]bool, len(desiredApplications))
+	for _, app := range desiredApplications {
+		desired[app.Name] = true
+	}
+
+	deletions := 0
+	for _, app := range currentApplications {
+		if !desired[app.Name] {
+			deletions++
+		}
+	}
+	if deletions == 0 {
+		return "", nil
+	}
+
+	if safeguards.RefuseEmptyGeneration && len(desiredApplications) == 0 {
+		return fmt.Sprintf("the generators didn't generate any Application, refusing to delete the %d current Applications", deletions), nil
+	}
+
+	if safeguards.MaxDeletions != nil {
+		maxDeletions, err := intstr.GetValueFromIntOrPercent(safeguards.MaxDeletions, len(currentApplications), false)
+		if err != nil {
+			return "", fmt.Errorf("invalid maxDeletions: %w", err)
+		}
+		if deletions > maxDeletions {
+			return fmt.Sprintf("%d of the %d current Applications would be deleted, more than the maximum of %d", deletions, len(currentApplications), maxDeletions), nil
+		}
+	}
+
+	return "", nil
+}
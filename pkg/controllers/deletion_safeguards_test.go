@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestGetDeletionBlockedMessage(t *testing.T) {
+	apps := func(names ...string) []argov1alpha1.Application {
+		res := []argov1alpha1.Application{}
+		for _, name := range names {
+			res = append(res, argov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		return res
+	}
+	value := func(v intstr.IntOrString) *intstr.IntOrString {
+		return &v
+	}
+
+	for _, c := range []struct {
+		name       string
+		safeguards argoprojiov1alpha1.ApplicationSetDeletionSafeguards
+		desired    []argov1alpha1.Application
+		current    []argov1alpha1.Application
+		expected   string
+	}{
+		{
+			name:       "no deletion",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{RefuseEmptyGeneration: true, MaxDeletions: value(intstr.FromInt(0))},
+			desired:    apps("a", "b"),
+			current:    apps("a"),
+			expected:   "",
+		},
+		{
+			name:       "empty generation",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{RefuseEmptyGeneration: true},
+			desired:    apps(),
+			current:    apps("a", "b"),
+			expected:   "the generators didn't generate any Application, refusing to delete the 2 current Applications",
+		},
+		{
+			name:       "empty generation allowed",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{},
+			desired:    apps(),
+			current:    apps("a", "b"),
+			expected:   "",
+		},
+		{
+			name:       "within max deletions",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{MaxDeletions: value(intstr.FromInt(1))},
+			desired:    apps("a", "b"),
+			current:    apps("a", "b", "c"),
+			expected:   "",
+		},
+		{
+			name:       "more than max deletions",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{MaxDeletions: value(intstr.FromInt(1))},
+			desired:    apps("a"),
+			current:    apps("a", "b", "c"),
+			expected:   "2 of the 3 current Applications would be deleted, more than the maximum of 1",
+		},
+		{
+			name:       "more than max deletions percentage",
+			safeguards: argoprojiov1alpha1.ApplicationSetDeletionSafeguards{MaxDeletions: value(intstr.FromString("25%"))},
+			desired:    apps("a", "b"),
+			current:    apps("a", "b", "c", "d"),
+			expected:   "2 of the 4 current Applications would be deleted, more than the maximum of 1",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := getDeletionBlockedMessage(&c.safeguards, c.desired, c.current)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, got)
+		})
+	}
+
+	_, err := getDeletionBlockedMessage(&argoprojiov1alpha1.ApplicationSetDeletionSafeguards{MaxDeletions: value(intstr.FromString("all"))}, apps(), apps("a"))
+	assert.Error(t, err)
+}
+
+func TestClearDeletionAcknowledgement(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+			Annotations: map[string]string{
+				argoprojiov1alpha1.AnnotationAcknowledgeDeletion: "true",
+				"other": "value",
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appSet).Build()
+
+	r := ApplicationSetReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+
+	err = r.clearDeletionAcknowledgement(context.TODO(), &appSet)
+	assert.Nil(t, err)
+
+	var got argoprojiov1alpha1.ApplicationSet
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "name", Namespace: "namespace"}, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"other": "value"}, got.Annotations)
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/generators"
+)
+
+// DuckTypeWatcher watches the resources read by clusterDecisionResource generators, and requeues the ApplicationSets
+// reading a resource when it changes. The kinds of these resources are only known at runtime, so an informer is
+// started lazily for each kind, the first time an ApplicationSet reading it is reconciled.
+//
+// The informers queue the ApplicationSets to requeue on a work queue, which coalesces the pending requeues of an
+// ApplicationSet, instead of sending them to the controller directly: a busy controller would block the informers.
+type DuckTypeWatcher struct {
+	ctx       context.Context
+	client    client.Client
+	namespace string
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	queue     workqueue.Interface
+	events    chan event.GenericEvent
+
+	lock    sync.Mutex
+	watched map[schema.GroupVersionResource]bool
+}
+
+// NewDuckTypeWatcher returns a watcher for the resources in namespace, which is also the namespace of the
+// ConfigMaps describing them and of the ApplicationSets reading them. The informers are stopped when ctx is done.
+func NewDuckTypeWatcher(ctx context.Context, c client.Client, dynClient dynamic.Interface, namespace string) *DuckTypeWatcher {
+	w := &DuckTypeWatcher{
+		ctx:       ctx,
+		client:    c,
+		namespace: namespace,
+		factory:   dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, 0, namespace, nil),
+		queue:     workqueue.New(),
+		events:    make(chan event.GenericEvent),
+		watched:   map[schema.GroupVersionResource]bool{},
+	}
+	go func() {
+		<-ctx.Done()
+		w.queue.ShutDown()
+	}()
+	go w.sendEvents()
+	return w
+}
+
+// Events returns the channel on which the ApplicationSets to requeue are sent.
+func (w *DuckTypeWatcher) Events() <-chan event.GenericEvent {
+	return w.events
+}
+
+// WatchApplicationSet starts watching the resources read by the clusterDecisionResource generators of the
+// ApplicationSet, if they are not watched already.
+func (w *DuckTypeWatcher) WatchApplicationSet(ctx context.Context, appSet *argoprojiov1alpha1.ApplicationSet) error {
+	for _, generator := range getDuckTypeGenerators(appSet) {
+		resource, err := generators.GetDuckTypeResource(ctx, w.client, generator.ConfigMapRef, w.namespace)
+		if err != nil {
+			return err
+		}
+		w.watch(resource.GroupVersionResource)
+	}
+	return nil
+}
+
+func (w *DuckTypeWatcher) watch(gvr schema.GroupVersionResource) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.watched[gvr] {
+		return
+	}
+
+	log.WithField("resource", gvr.String()).Info("watching cluster decision resource")
+
+	informer := w.factory.ForResource(gvr).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.queueRelatedApplicationSets(gvr, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			w.queueRelatedApplicationSets(gvr, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			w.queueRelatedApplicationSets(gvr, obj)
+		},
+	})
+	w.factory.Start(w.ctx.Done())
+
+	w.watched[gvr] = true
+}
+
+func (w *DuckTypeWatcher) queueRelatedApplicationSets(gvr schema.GroupVersionResource, obj interface{}) {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		log.WithError(err).Warn("unable to read cluster decision resource metadata")
+		return
+	}
+
+	appSets, err := w.applicationSetsForObject(w.ctx, gvr, object)
+	if err != nil {
+		log.WithError(err).Error("unable to list ApplicationSets")
+		return
+	}
+
+	for _, appSet := range appSets {
+		w.queue.Add(types.NamespacedName{Namespace: appSet.Namespace, Name: appSet.Name})
+	}
+}
+
+// sendEvents sends the ApplicationSets of the queue to the controller, until the queue is shut down.
+func (w *DuckTypeWatcher) sendEvents() {
+	for {
+		item, shutdown := w.queue.Get()
+		if shutdown {
+			return
+		}
+		key := item.(types.NamespacedName)
+		appSet := &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		select {
+		case w.events <- event.GenericEvent{Object: appSet}:
+		case <-w.ctx.Done():
+		}
+		w.queue.Done(item)
+	}
+}
+
+// applicationSetsForObject returns the ApplicationSets with a clusterDecisionResource generator reading the object.
+func (w *DuckTypeWatcher) applicationSetsForObject(ctx context.Context, gvr schema.GroupVersionResource, object metav1.Object) ([]argoprojiov1alpha1.ApplicationSet, error) {
+	appSetList := &argoprojiov1alpha1.ApplicationSetList{}
+	if err := w.client.List(ctx, appSetList, client.InNamespace(w.namespace)); err != nil {
+		return nil, err
+	}
+
+	var res []argoprojiov1alpha1.ApplicationSet
+	for _, appSet := range appSetList.Items {
+		for _, generator := range getDuckTypeGenerators(&appSet) {
+			resource, err := generators.GetDuckTypeResource(ctx, w.client, generator.ConfigMapRef, w.namespace)
+			if err != nil || resource.GroupVersionResource != gvr {
+				continue
+			}
+
+			if generator.Name != "" {
+				if generator.Name != object.GetName() {
+					continue
+				}
+			} else {
+				selector, err := metav1.LabelSelectorAsSelector(&generator.LabelSelector)
+				if err != nil || !selector.Matches(labels.Set(object.GetLabels())) {
+					continue
+				}
+			}
+
+			res = append(res, appSet)
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// getDuckTypeGenerators returns the clusterDecisionResource generators of the ApplicationSet, including the ones
+// nested within matrix and merge generators.
+func getDuckTypeGenerators(appSet *argoprojiov1alpha1.ApplicationSet) []*argoprojiov1alpha1.DuckTypeGenerator {
+	var res []*argoprojiov1alpha1.DuckTypeGenerator
+
+	for _, generator := range appSet.Spec.Generators {
+		if generator.ClusterDecisionResource != nil {
+			res = append(res, generator.ClusterDecisionResource)
+		}
+
+		var nestedGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		if generator.Matrix != nil {
+			nestedGenerators = append(nestedGenerators, generator.Matrix.Generators...)
+		}
+		if generator.Merge != nil {
+			nestedGenerators = append(nestedGenerators, generator.Merge.Generators...)
+		}
+		for _, nestedGenerator := range nestedGenerators {
+			if nestedGenerator.ClusterDecisionResource != nil {
+				res = append(res, nestedGenerator.ClusterDecisionResource)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestDuckTypeWatcherApplicationSetsForObject(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = corev1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-configmap", Namespace: "argocd"},
+		Data: map[string]string{
+			"apiVersion":    "mallard.io/v1",
+			"resource":      "ducks",
+			"statusListKey": "decisions",
+			"matchKey":      "clusterName",
+		},
+	}
+
+	byName := &argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "by-name", Namespace: "argocd"},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators: []argoprojiov1alpha1.ApplicationSetGenerator{
+				{ClusterDecisionResource: &argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "my-configmap", Name: "duck-a"}},
+			},
+		},
+	}
+	bySelectorInMatrix := &argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "by-selector", Namespace: "argocd"},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators: []argoprojiov1alpha1.ApplicationSetGenerator{
+				{Matrix: &argoprojiov1alpha1.MatrixGenerator{
+					Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+						{List: &argoprojiov1alpha1.ListGenerator{}},
+						{ClusterDecisionResource: &argoprojiov1alpha1.DuckTypeGenerator{
+							ConfigMapRef:  "my-configmap",
+							LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"duck": "spotted"}},
+						}},
+					},
+				}},
+			},
+		},
+	}
+	unrelated := &argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "argocd"},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators: []argoprojiov1alpha1.ApplicationSetGenerator{
+				{List: &argoprojiov1alpha1.ListGenerator{}},
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, byName, bySelectorInMatrix, unrelated).Build()
+	watcher := NewDuckTypeWatcher(context.Background(), client, nil, "argocd")
+	gvr := schema.GroupVersionResource{Group: "mallard.io", Version: "v1", Resource: "ducks"}
+
+	testCases := []struct {
+		name     string
+		gvr      schema.GroupVersionResource
+		object   metav1.ObjectMeta
+		expected []string
+	}{
+		{
+			name:     "matches by name",
+			gvr:      gvr,
+			object:   metav1.ObjectMeta{Name: "duck-a", Namespace: "argocd"},
+			expected: []string{"by-name"},
+		},
+		{
+			name:     "matches by label selector of a nested generator",
+			gvr:      gvr,
+			object:   metav1.ObjectMeta{Name: "duck-b", Namespace: "argocd", Labels: map[string]string{"duck": "spotted"}},
+			expected: []string{"by-selector"},
+		},
+		{
+			name:     "other resource kind",
+			gvr:      schema.GroupVersionResource{Group: "mallard.io", Version: "v1", Resource: "geese"},
+			object:   metav1.ObjectMeta{Name: "duck-a", Namespace: "argocd"},
+			expected: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			object := testCase.object
+			appSets, err := watcher.applicationSetsForObject(context.Background(), testCase.gvr, &object)
+			assert.NoError(t, err)
+
+			names := []string{}
+			for _, appSet := range appSets {
+				names = append(names, appSet.Name)
+			}
+			assert.ElementsMatch(t, testCase.expected, names)
+		})
+	}
+}
+
+func TestDuckTypeWatcherCoalescesEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	watcher := NewDuckTypeWatcher(ctx, client, nil, "argocd")
+
+	// The requeues queued while the controller isn't receiving are coalesced, without blocking the informers
+	first := types.NamespacedName{Namespace: "argocd", Name: "first"}
+	second := types.NamespacedName{Namespace: "argocd", Name: "second"}
+	for i := 0; i < 2000; i++ {
+		watcher.queue.Add(first)
+		watcher.queue.Add(second)
+	}
+
+	received := map[types.NamespacedName]int{}
+	for len(received) < 2 {
+		select {
+		case e := <-watcher.Events():
+			received[types.NamespacedName{Namespace: e.Object.GetNamespace(), Name: e.Object.GetName()}]++
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the events")
+		}
+	}
+	// The first event may have been taken from the queue before the following requeues were queued
+	assert.LessOrEqual(t, received[first]+received[second], 3)
+
+	select {
+	case e := <-watcher.Events():
+		key := types.NamespacedName{Namespace: e.Object.GetNamespace(), Name: e.Object.GetName()}
+		assert.Equal(t, 1, received[key], "unexpected event for %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
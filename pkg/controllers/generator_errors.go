@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// generateApplicationsIsolated returns the Applications generated by the generators that succeeded, annotated with
+// the hash of their generator, and the errors of the generators that failed, by index.
+func (r *ApplicationSetReconciler) generateApplicationsIsolated(applicationSetInfo argoprojiov1alpha1.ApplicationSet) ([]argov1alpha1.Application, map[int]error) {
+	res := []argov1alpha1.Application{}
+	generatorErrors := map[int]error{}
+
+	for i := range applicationSetInfo.Spec.Generators {
+		hash, err := hashGenerator(&applicationSetInfo.Spec.Generators[i])
+		if err != nil {
+			generatorErrors[i] = err
+			continue
+		}
+		apps, err := r.generateApplicationsForGenerator(applicationSetInfo, &applicationSetInfo.Spec.Generators[i])
+		if err != nil {
+			generatorErrors[i] = err
+			continue
+		}
+
+		for _, app := range apps {
+			annotations := make(map[string]string, len(app.Annotations)+1)
+			for key, value := range app.Annotations {
+				annotations[key] = value
+			}
+			annotations[argoprojiov1alpha1.AnnotationGeneratorHash] = hash
+			app.Annotations = annotations
+			res = append(res, app)
+		}
+	}
+
+	return res, generatorErrors
+}
+
+// hashGenerator returns the hash of the spec of a generator, which identifies its Applications regardless of its
+// position in spec.generators.
+func hashGenerator(generator *argoprojiov1alpha1.ApplicationSetGenerator) (string, error) {
+	generatorBytes, err := json.Marshal(generator)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(generatorBytes)), nil
+}
+
+// getProtectedApplications returns the current Applications that may belong to a failed generator: those that aren't
+// annotated with the hash of a generator that succeeded. This includes the Applications of generators that were
+// changed or removed since, whose generator is unknown, until every generator succeeds again.
+func getProtectedApplications(currentApplications []argov1alpha1.Application, generators []argoprojiov1alpha1.ApplicationSetGenerator, generatorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError) []argov1alpha1.Application {
+	failedGenerators := make(map[int]bool, len(generatorErrors))
+	for _, generatorError := range generatorErrors {
+		failedGenerators[generatorError.Generator] = true
+	}
+
+	failed := map[string]bool{}
+	succeeded := map[string]bool{}
+	for i := range generators {
+		hash, err := hashGenerator(&generators[i])
+		if err != nil {
+			continue
+		}
+		if failedGenerators[i] {
+			failed[hash] = true
+		} else {
+			succeeded[hash] = true
+		}
+	}
+
+	var res []argov1alpha1.Application
+	for _, app := range currentApplications {
+		hash := app.Annotations[argoprojiov1alpha1.AnnotationGeneratorHash]
+		if failed[hash] || !succeeded[hash] {
+			res = append(res, app)
+		}
+	}
+	return res
+}
+
+// excludeApplications returns the Applications without those named like an excluded Application.
+func excludeApplications(applications, excluded []argov1alpha1.Application) []argov1alpha1.Application {
+	names := make(map[string]bool, len(excluded))
+	for _, app := range excluded {
+		names[app.Name] = true
+	}
+
+	res := make([]argov1alpha1.Application, 0, len(applications))
+	for _, app := range applications {
+		if !names[app.Name] {
+			res = append(res, app)
+		}
+	}
+	return res
+}
+
+// getGeneratorErrorStatuses returns the status of the failed generators, sorted by index.
+func getGeneratorErrorStatuses(generatorErrors map[int]error) []argoprojiov1alpha1.ApplicationSetGeneratorError {
+	if len(generatorErrors) == 0 {
+		return nil
+	}
+
+	res := make([]argoprojiov1alpha1.ApplicationSetGeneratorError, 0, len(generatorErrors))
+	for index, err := range generatorErrors {
+		res = append(res, argoprojiov1alpha1.ApplicationSetGeneratorError{Generator: index, Message: err.Error()})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Generator < res[j].Generator
+	})
+	return res
+}
+
+// generatorsError combines the errors of the failed generators.
+func generatorsError(statuses []argoprojiov1alpha1.ApplicationSetGeneratorError) error {
+	messages := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		messages = append(messages, fmt.Sprintf("generator %d: %s", status.Generator, status.Message))
+	}
+	return fmt.Errorf("%d generators failed, their Applications were left untouched: %s", len(statuses), strings.Join(messages, "; "))
+}
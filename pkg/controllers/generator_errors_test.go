@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/generators"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+func TestGenerateApplicationsIsolated(t *testing.T) {
+	listGenerator := argoprojiov1alpha1.ApplicationSetGenerator{
+		List: &argoprojiov1alpha1.ListGenerator{},
+	}
+	clusterGenerator := argoprojiov1alpha1.ApplicationSetGenerator{
+		Clusters: &argoprojiov1alpha1.ClusterGenerator{},
+	}
+
+	listMock := generatorMock{}
+	listMock.On("GenerateParams", &listGenerator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}{{"name": "app1"}, {"name": "app2"}}, nil)
+	listMock.On("GetTemplate", &listGenerator).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+	listMock.On("GetFilters", &listGenerator).
+		Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
+	clusterMock := generatorMock{}
+	clusterMock.On("GenerateParams", &clusterGenerator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}(nil), errors.New("unable to list clusters"))
+	clusterMock.On("GetTemplate", &clusterGenerator).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+
+	r := ApplicationSetReconciler{
+		Generators: map[string]generators.Generator{
+			"List":     &listMock,
+			"Clusters": &clusterMock,
+		},
+		Renderer: &utils.Render{},
+	}
+
+	apps, generatorErrors := r.generateApplicationsIsolated(argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators: []argoprojiov1alpha1.ApplicationSetGenerator{clusterGenerator, listGenerator},
+			Template: argoprojiov1alpha1.ApplicationSetTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "{{name}}",
+					Annotations: map[string]string{"key": "value"},
+				},
+			},
+		},
+	})
+
+	listHash, err := hashGenerator(&listGenerator)
+	assert.Nil(t, err)
+	if assert.Len(t, apps, 2) {
+		assert.Equal(t, "app1", apps[0].Name)
+		assert.Equal(t, map[string]string{"key": "value", argoprojiov1alpha1.AnnotationGeneratorHash: listHash}, apps[0].Annotations)
+		assert.Equal(t, "app2", apps[1].Name)
+	}
+	assert.Equal(t, map[int]error{0: errors.New("unable to list clusters")}, generatorErrors)
+}
+
+func TestGetProtectedApplications(t *testing.T) {
+	app := func(name string, annotations map[string]string) argov1alpha1.Application {
+		return argov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	generators := []argoprojiov1alpha1.ApplicationSetGenerator{
+		{Clusters: &argoprojiov1alpha1.ClusterGenerator{}},
+		{List: &argoprojiov1alpha1.ListGenerator{}},
+	}
+	hash := func(generator argoprojiov1alpha1.ApplicationSetGenerator) string {
+		res, err := hashGenerator(&generator)
+		assert.Nil(t, err)
+		return res
+	}
+	changedGenerator := argoprojiov1alpha1.ApplicationSetGenerator{
+		List: &argoprojiov1alpha1.ListGenerator{Elements: []argoprojiov1alpha1.ListGeneratorElement{{Cluster: "removed"}}},
+	}
+
+	current := []argov1alpha1.Application{
+		app("failed", map[string]string{argoprojiov1alpha1.AnnotationGeneratorHash: hash(generators[0])}),
+		app("succeeded", map[string]string{argoprojiov1alpha1.AnnotationGeneratorHash: hash(generators[1])}),
+		app("changed-generator", map[string]string{argoprojiov1alpha1.AnnotationGeneratorHash: hash(changedGenerator)}),
+		app("unknown", nil),
+	}
+
+	names := func(apps []argov1alpha1.Application) []string {
+		res := []string{}
+		for _, app := range apps {
+			res = append(res, app.Name)
+		}
+		return res
+	}
+
+	got := getProtectedApplications(current, generators, []argoprojiov1alpha1.ApplicationSetGeneratorError{{Generator: 0, Message: "error"}})
+	assert.Equal(t, []string{"failed", "changed-generator", "unknown"}, names(got))
+
+	// The Applications follow their generator when the generators are reordered
+	reordered := []argoprojiov1alpha1.ApplicationSetGenerator{generators[1], generators[0]}
+	assert.Equal(t, names(got), names(getProtectedApplications(current, reordered, []argoprojiov1alpha1.ApplicationSetGeneratorError{{Generator: 1, Message: "error"}})))
+
+	assert.Equal(t, []argov1alpha1.Application{current[1]}, excludeApplications(current[:2], got))
+}
+
+func TestGeneratorErrorStatuses(t *testing.T) {
+	assert.Nil(t, getGeneratorErrorStatuses(nil))
+
+	statuses := getGeneratorErrorStatuses(map[int]error{
+		2: errors.New("repository not found"),
+		0: errors.New("unable to list clusters"),
+	})
+
+	assert.Equal(t, []argoprojiov1alpha1.ApplicationSetGeneratorError{
+		{Generator: 0, Message: "unable to list clusters"},
+		{Generator: 2, Message: "repository not found"},
+	}, statuses)
+	assert.EqualError(t, generatorsError(statuses),
+		"2 generators failed, their Applications were left untouched: generator 0: unable to list clusters; generator 2: repository not found")
+}
@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+const (
+	// PreviewPath is the path on which the ApplicationSet manifests to preview are received
+	PreviewPath = "/api/preview"
+
+	// maxManifestSize is the size above which the manifests to preview are refused
+	maxManifestSize = 1024 * 1024
+)
+
+// ApplicationSetPreview describes the changes the reconciliation of an ApplicationSet would make to its Applications.
+type ApplicationSetPreview struct {
+	// Create lists the Applications that would be created.
+	Create []argov1alpha1.Application `json:"create,omitempty"`
+	// Update lists the Applications that would be updated.
+	Update []ApplicationUpdatePreview `json:"update,omitempty"`
+	// Delete lists the Applications that would be deleted.
+	Delete []argov1alpha1.Application `json:"delete,omitempty"`
+	// Unchanged lists the names of the Applications that would be left as they are.
+	Unchanged []string `json:"unchanged,omitempty"`
+	// GeneratorErrors lists the generators that failed, with IsolateGeneratorErrors. The Applications that may belong
+	// to them are left as they are.
+	GeneratorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError `json:"generatorErrors,omitempty"`
+}
+
+// ApplicationUpdatePreview describes the update of an Application.
+type ApplicationUpdatePreview struct {
+	Current argov1alpha1.Application `json:"current"`
+	Desired argov1alpha1.Application `json:"desired"`
+	// ChangedFields are the paths of the fields that would change, e.g. spec.source.targetRevision, sorted.
+	ChangedFields []string `json:"changedFields"`
+}
+
+// Preview returns the changes the reconciliation of the ApplicationSet would make to its Applications, within the
+// policy of the controller and the ApplicationSet, without making them. The preview is the state the Applications
+// eventually reach: it ignores the steps of the RollingUpdate strategy and the DeletionSafeguards.
+func (r *ApplicationSetReconciler) Preview(ctx context.Context, applicationSet *argoprojiov1alpha1.ApplicationSet) (*ApplicationSetPreview, error) {
+	desiredApplications, generatorErrors, err := r.GenerateApplications(applicationSet)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := r.getCurrentApplications(ctx, *applicationSet)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted, err := r.getDriftedApplications(ctx, *applicationSet, desiredApplications)
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]bool{}
+	if !isSelfHeal(applicationSet) {
+		for _, app := range drifted {
+			skipped[app.Name] = true
+		}
+	}
+
+	return r.diffApplications(applicationSet, current, desiredApplications, generatorErrors, skipped)
+}
+
+// GenerateApplications returns the Applications rendered by the generators of the ApplicationSet, in its namespace.
+// Like the reconciliation, it fails when Applications have the same name, and when a generator fails unless
+// IsolateGeneratorErrors is set: the failed generators are then returned with the Applications of the others, which
+// are annotated with the hash of their generator.
+func (r *ApplicationSetReconciler) GenerateApplications(applicationSet *argoprojiov1alpha1.ApplicationSet) ([]argov1alpha1.Application, []argoprojiov1alpha1.ApplicationSetGeneratorError, error) {
+	var res []argov1alpha1.Application
+	var generatorErrors map[int]error
+	if applicationSet.Spec.IsolateGeneratorErrors {
+		res, generatorErrors = r.generateApplicationsIsolated(*applicationSet)
+	} else {
+		var err error
+		res, err = r.generateApplications(*applicationSet)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasDuplicates, name := hasDuplicateNames(res); hasDuplicates {
+		return nil, nil, fmt.Errorf("ApplicationSet %s contains applications with duplicate name: %s", applicationSet.Name, name)
+	}
+
+	for i := range res {
+		res[i].Namespace = applicationSet.Namespace
+	}
+	return res, getGeneratorErrorStatuses(generatorErrors), nil
+}
+
+// DiffApplications returns the changes turning the current Applications of the ApplicationSet into the desired ones,
+// within the policy of the controller and the ApplicationSet, given the generators that failed, see
+// GenerateApplications. Unlike Preview, the manual changes to the current Applications are always reverted.
+func (r *ApplicationSetReconciler) DiffApplications(applicationSet *argoprojiov1alpha1.ApplicationSet, current, desired []argov1alpha1.Application, generatorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError) (*ApplicationSetPreview, error) {
+	return r.diffApplications(applicationSet, current, desired, generatorErrors, nil)
+}
+
+// diffApplications returns the changes turning the current Applications into the desired ones, leaving the skipped
+// ones unchanged. Like the reconciliation, the Applications that may belong to a failed generator are left unchanged.
+func (r *ApplicationSetReconciler) diffApplications(applicationSet *argoprojiov1alpha1.ApplicationSet, current, desiredApplications []argov1alpha1.Application, generatorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError, skipped map[string]bool) (*ApplicationSetPreview, error) {
+	live := make(map[string]*argov1alpha1.Application, len(current))
+	for i := range current {
+		live[current[i].Name] = &current[i]
+	}
+
+	policy := r.getPolicy(applicationSet)
+	res := &ApplicationSetPreview{GeneratorErrors: generatorErrors}
+	desired := make(map[string]bool, len(desiredApplications))
+	if len(generatorErrors) > 0 {
+		protected := getProtectedApplications(current, applicationSet.Spec.Generators, generatorErrors)
+		desiredApplications = excludeApplications(desiredApplications, protected)
+		for _, app := range protected {
+			desired[app.Name] = true
+			res.Unchanged = append(res.Unchanged, app.Name)
+		}
+	}
+	for i := range desiredApplications {
+		app := desiredApplications[i]
+		desired[app.Name] = true
+
+		found, exists := live[app.Name]
+		if !exists {
+			res.Create = append(res.Create, app)
+			continue
+		}
+		if !policy.Update() || skipped[app.Name] {
+			res.Unchanged = append(res.Unchanged, app.Name)
+			continue
+		}
+
+		updated := found.DeepCopy()
+		if err := mergeDesiredApplication(applicationSet, &app, updated); err != nil {
+			return nil, err
+		}
+		changedFields, err := getChangedFields(found, updated)
+		if err != nil {
+			return nil, err
+		}
+		if len(changedFields) == 0 {
+			res.Unchanged = append(res.Unchanged, app.Name)
+			continue
+		}
+		res.Update = append(res.Update, ApplicationUpdatePreview{Current: *found, Desired: *updated, ChangedFields: changedFields})
+	}
+
+	if policy.Delete() {
+		for _, app := range current {
+			if !desired[app.Name] {
+				res.Delete = append(res.Delete, app)
+			}
+		}
+	}
+
+	sort.Strings(res.Unchanged)
+	return res, nil
+}
+
+// getChangedFields returns the paths of the fields managed by the ApplicationSet that differ between the
+// Applications: the spec, the annotations and the labels.
+func getChangedFields(current, desired *argov1alpha1.Application) ([]string, error) {
+	currentFields, err := getManagedFields(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := getManagedFields(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	diffFields("", currentFields, desiredFields, &res)
+	sort.Strings(res)
+	return res, nil
+}
+
+func getManagedFields(app *argov1alpha1.Application) (interface{}, error) {
+	fieldsBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": app.Annotations,
+			"labels":      app.Labels,
+		},
+		"spec": app.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(fieldsBytes, &res)
+	return res, err
+}
+
+// diffFields appends to res the paths of the values that differ, descending into the objects. Missing, null and
+// empty values are equal.
+func diffFields(path string, current, desired interface{}, res *[]string) {
+	if isEmptyField(current) && isEmptyField(desired) {
+		return
+	}
+
+	currentObject, currentIsObject := current.(map[string]interface{})
+	desiredObject, desiredIsObject := desired.(map[string]interface{})
+	if isEmptyField(current) && desiredIsObject {
+		currentObject, currentIsObject = map[string]interface{}{}, true
+	}
+	if isEmptyField(desired) && currentIsObject {
+		desiredObject, desiredIsObject = map[string]interface{}{}, true
+	}
+	if !currentIsObject || !desiredIsObject {
+		if !reflect.DeepEqual(current, desired) {
+			*res = append(*res, path)
+		}
+		return
+	}
+
+	keys := map[string]bool{}
+	for key := range currentObject {
+		keys[key] = true
+	}
+	for key := range desiredObject {
+		keys[key] = true
+	}
+	for key := range keys {
+		diffFields(strings.TrimPrefix(path+"."+key, "."), currentObject[key], desiredObject[key], res)
+	}
+}
+
+func isEmptyField(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// previewHandler previews the ApplicationSet manifests POSTed to it.
+type previewHandler struct {
+	reconciler *ApplicationSetReconciler
+	namespace  string
+}
+
+// NewPreviewHandler returns an HTTP handler previewing the ApplicationSet manifests, in YAML or JSON, POSTed to it.
+// The ApplicationSets must be in namespace, the namespace of the controller, which is the default. It responds with
+// the ApplicationSetPreview, in JSON.
+//
+// The generators read the repositories and the Secrets with the credentials of the controller, so the handler must
+// not be exposed to the users that can't read them.
+func NewPreviewHandler(r *ApplicationSetReconciler, namespace string) http.Handler {
+	return &previewHandler{reconciler: r, namespace: namespace}
+}
+
+func (h *previewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	manifest, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read the manifest: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var applicationSet argoprojiov1alpha1.ApplicationSet
+	if err := yaml.Unmarshal(manifest, &applicationSet); err != nil {
+		http.Error(w, fmt.Sprintf("invalid ApplicationSet manifest: %v", err), http.StatusBadRequest)
+		return
+	}
+	if applicationSet.Kind != "" && applicationSet.Kind != "ApplicationSet" {
+		http.Error(w, fmt.Sprintf("expected an ApplicationSet, got a %s", applicationSet.Kind), http.StatusBadRequest)
+		return
+	}
+	if applicationSet.Namespace == "" {
+		applicationSet.Namespace = h.namespace
+	}
+	if applicationSet.Namespace != h.namespace {
+		http.Error(w, fmt.Sprintf("the ApplicationSet must be in the %s namespace", h.namespace), http.StatusBadRequest)
+		return
+	}
+
+	preview, err := h.reconciler.Preview(r.Context(), &applicationSet)
+	if err != nil {
+		log.WithField("appSet", applicationSet.Name).WithError(err).Info("unable to preview ApplicationSet")
+		http.Error(w, fmt.Sprintf("unable to preview the ApplicationSet: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		log.WithField("appSet", applicationSet.Name).WithError(err).Warn("unable to write the preview")
+	}
+}
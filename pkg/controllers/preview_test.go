@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/generators"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+func TestPreview(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators: []argoprojiov1alpha1.ApplicationSetGenerator{
+				{List: &argoprojiov1alpha1.ListGenerator{}},
+			},
+			Template: argoprojiov1alpha1.ApplicationSetTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "{{name}}",
+					Labels: map[string]string{"env": "{{env}}"},
+				},
+				Spec: argov1alpha1.ApplicationSpec{Project: "{{project}}"},
+			},
+		},
+	}
+
+	app := func(name, env, project string) argov1alpha1.Application {
+		return argov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "namespace", Labels: map[string]string{"env": env}},
+			Spec:       argov1alpha1.ApplicationSpec{Project: project},
+		}
+	}
+	current := []argov1alpha1.Application{
+		app("unchanged", "prod", "default"),
+		app("updated", "dev", "default"),
+		app("deleted", "prod", "default"),
+	}
+
+	initObjs := []client.Object{&appSet}
+	for i := range current {
+		err = controllerutil.SetControllerReference(&appSet, &current[i], scheme)
+		assert.Nil(t, err)
+		initObjs = append(initObjs, &current[i])
+	}
+
+	listMock := generatorMock{}
+	listMock.On("GenerateParams", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator"), mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}{
+			{"name": "unchanged", "env": "prod", "project": "default"},
+			{"name": "updated", "env": "prod", "project": "other"},
+			{"name": "created", "env": "prod", "project": "default"},
+		}, nil)
+	listMock.On("GetTemplate", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator")).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+	listMock.On("GetFilters", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator")).
+		Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
+	for _, c := range []struct {
+		name            string
+		policy          utils.Policy
+		expectedCreate  []string
+		expectedUpdate  []string
+		expectedDelete  []string
+		expectedChanges []string
+		expectedSame    []string
+	}{
+		{
+			name:            "sync",
+			policy:          &utils.SyncPolicy{},
+			expectedCreate:  []string{"created"},
+			expectedUpdate:  []string{"updated"},
+			expectedDelete:  []string{"deleted"},
+			expectedChanges: []string{"metadata.labels.env", "spec.project"},
+			expectedSame:    []string{"unchanged"},
+		},
+		{
+			name:           "create-only",
+			policy:         &utils.CreateOnlyPolicy{},
+			expectedCreate: []string{"created"},
+			expectedSame:   []string{"unchanged", "updated"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
+
+			r := ApplicationSetReconciler{
+				Client:     client,
+				Scheme:     scheme,
+				Generators: map[string]generators.Generator{"List": &listMock},
+				Renderer:   &utils.Render{},
+				Policy:     c.policy,
+			}
+
+			preview, err := r.Preview(context.TODO(), &appSet)
+			assert.Nil(t, err)
+
+			names := func(apps []argov1alpha1.Application) []string {
+				var res []string
+				for _, app := range apps {
+					res = append(res, app.Name)
+				}
+				return res
+			}
+			assert.Equal(t, c.expectedCreate, names(preview.Create))
+			assert.Equal(t, c.expectedDelete, names(preview.Delete))
+			assert.Equal(t, c.expectedSame, preview.Unchanged)
+
+			var updated []string
+			for _, update := range preview.Update {
+				updated = append(updated, update.Current.Name)
+				assert.Equal(t, c.expectedChanges, update.ChangedFields)
+				assert.Equal(t, "other", update.Desired.Spec.Project)
+			}
+			assert.Equal(t, c.expectedUpdate, updated)
+
+			// Nothing was applied
+			var got argov1alpha1.ApplicationList
+			err = client.List(context.TODO(), &got)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"deleted", "unchanged", "updated"}, names(got.Items))
+		})
+	}
+}
+
+func TestPreviewIsolateGeneratorErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	listGenerator := argoprojiov1alpha1.ApplicationSetGenerator{
+		List: &argoprojiov1alpha1.ListGenerator{},
+	}
+	clusterGenerator := argoprojiov1alpha1.ApplicationSetGenerator{
+		Clusters: &argoprojiov1alpha1.ClusterGenerator{},
+	}
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			Generators:             []argoprojiov1alpha1.ApplicationSetGenerator{listGenerator, clusterGenerator},
+			IsolateGeneratorErrors: true,
+			Template: argoprojiov1alpha1.ApplicationSetTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "{{name}}"},
+				Spec:       argov1alpha1.ApplicationSpec{Project: "default"},
+			},
+		},
+	}
+
+	// The live Applications were annotated with the hash of their generator by the reconciliation
+	listHash, err := hashGenerator(&listGenerator)
+	assert.Nil(t, err)
+	clusterHash, err := hashGenerator(&clusterGenerator)
+	assert.Nil(t, err)
+	current := []argov1alpha1.Application{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "list-app", Namespace: "namespace", Annotations: map[string]string{argoprojiov1alpha1.AnnotationGeneratorHash: listHash}},
+			Spec:       argov1alpha1.ApplicationSpec{Project: "default"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-app", Namespace: "namespace", Annotations: map[string]string{argoprojiov1alpha1.AnnotationGeneratorHash: clusterHash}},
+			Spec:       argov1alpha1.ApplicationSpec{Project: "default"},
+		},
+	}
+	initObjs := []client.Object{&appSet}
+	for i := range current {
+		err = controllerutil.SetControllerReference(&appSet, &current[i], scheme)
+		assert.Nil(t, err)
+		initObjs = append(initObjs, &current[i])
+	}
+
+	listMock := generatorMock{}
+	listMock.On("GenerateParams", &listGenerator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}{{"name": "list-app"}}, nil)
+	listMock.On("GetTemplate", &listGenerator).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+	listMock.On("GetFilters", &listGenerator).
+		Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
+	clusterMock := generatorMock{}
+	clusterMock.On("GenerateParams", &clusterGenerator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}{{"name": "cluster-app"}}, nil)
+	clusterMock.On("GetTemplate", &clusterGenerator).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+	clusterMock.On("GetFilters", &clusterGenerator).
+		Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
+	failingClusterMock := generatorMock{}
+	failingClusterMock.On("GenerateParams", &clusterGenerator, mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}(nil), errors.New("unable to list clusters"))
+	failingClusterMock.On("GetTemplate", &clusterGenerator).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+
+	for _, c := range []struct {
+		name                    string
+		clusterGenerator        generators.Generator
+		expectedGeneratorErrors []argoprojiov1alpha1.ApplicationSetGeneratorError
+	}{
+		{
+			name:             "generators succeed",
+			clusterGenerator: &clusterMock,
+		},
+		{
+			name:                    "generator fails",
+			clusterGenerator:        &failingClusterMock,
+			expectedGeneratorErrors: []argoprojiov1alpha1.ApplicationSetGeneratorError{{Generator: 1, Message: "unable to list clusters"}},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := ApplicationSetReconciler{
+				Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build(),
+				Scheme:     scheme,
+				Generators: map[string]generators.Generator{"List": &listMock, "Clusters": c.clusterGenerator},
+				Renderer:   &utils.Render{},
+				Policy:     &utils.SyncPolicy{},
+			}
+
+			// The Applications of the failed generator are neither updated nor deleted, like by the reconciliation
+			preview, err := r.Preview(context.TODO(), &appSet)
+			assert.Nil(t, err)
+			assert.Empty(t, preview.Create)
+			assert.Empty(t, preview.Update)
+			assert.Empty(t, preview.Delete)
+			assert.Equal(t, []string{"cluster-app", "list-app"}, preview.Unchanged)
+			assert.Equal(t, c.expectedGeneratorErrors, preview.GeneratorErrors)
+		})
+	}
+}
+
+func TestGetChangedFields(t *testing.T) {
+	current := argov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Annotations: map[string]string{}},
+		Spec: argov1alpha1.ApplicationSpec{
+			Project: "default",
+			Source:  argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: "HEAD"},
+		},
+	}
+
+	same := current.DeepCopy()
+	same.Annotations = nil
+	got, err := getChangedFields(&current, same)
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+
+	changed := current.DeepCopy()
+	changed.Labels = map[string]string{"env": "prod"}
+	changed.Spec.Source.TargetRevision = "v1.0.0"
+	changed.Spec.Source.Helm = &argov1alpha1.ApplicationSourceHelm{ReleaseName: "release"}
+	got, err = getChangedFields(&current, changed)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"metadata.labels.env", "spec.source.helm.releaseName", "spec.source.targetRevision"}, got)
+}
+
+func TestPreviewHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	listMock := generatorMock{}
+	listMock.On("GenerateParams", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator"), mock.AnythingOfType("*v1alpha1.ApplicationSet")).
+		Return([]map[string]interface{}{{"name": "app"}}, nil)
+	listMock.On("GetTemplate", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator")).
+		Return(&argoprojiov1alpha1.ApplicationSetTemplate{})
+	listMock.On("GetFilters", mock.AnythingOfType("*v1alpha1.ApplicationSetGenerator")).
+		Return([]argoprojiov1alpha1.GeneratorFilter(nil))
+
+	h := NewPreviewHandler(&ApplicationSetReconciler{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:     scheme,
+		Generators: map[string]generators.Generator{"List": &listMock},
+		Renderer:   &utils.Render{},
+		Policy:     &utils.SyncPolicy{},
+	}, "argocd")
+
+	manifest := `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: guestbook
+spec:
+  generators:
+  - list:
+      elements: []
+  template:
+    metadata:
+      name: '{{name}}'
+    spec:
+      project: default
+      destination:
+        server: https://kubernetes.default.svc
+`
+
+	for _, c := range []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+	}{
+		{"preview", http.MethodPost, manifest, http.StatusOK},
+		{"controller namespace", http.MethodPost, strings.Replace(manifest, "name: guestbook", "name: guestbook\n  namespace: argocd", 1), http.StatusOK},
+		{"wrong namespace", http.MethodPost, "metadata:\n  namespace: default\n", http.StatusBadRequest},
+		{"wrong kind", http.MethodPost, "kind: Application\n", http.StatusBadRequest},
+		{"invalid manifest", http.MethodPost, "spec: [", http.StatusBadRequest},
+		{"get", http.MethodGet, "", http.StatusMethodNotAllowed},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(c.method, PreviewPath, bytes.NewBufferString(c.body)))
+			assert.Equal(t, c.expectedStatus, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PreviewPath, bytes.NewBufferString(manifest)))
+	var preview ApplicationSetPreview
+	err = json.Unmarshal(rec.Body.Bytes(), &preview)
+	assert.Nil(t, err)
+	if assert.Len(t, preview.Create, 1) {
+		assert.Equal(t, "app", preview.Create[0].Name)
+		assert.Equal(t, "argocd", preview.Create[0].Namespace)
+		assert.Equal(t, "https://kubernetes.default.svc", preview.Create[0].Spec.Destination.Server)
+	}
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+// isRollingUpdate returns whether the Applications of the ApplicationSet are rolled out one step at a time.
+func isRollingUpdate(applicationSet *argoprojiov1alpha1.ApplicationSet) bool {
+	strategy := applicationSet.Spec.Strategy
+	return strategy != nil && strategy.Type == argoprojiov1alpha1.ApplicationSetStrategyTypeRollingUpdate
+}
+
+// rollOutInCluster creates or updates the desired Applications of the current step of the RollingUpdate strategy of
+// the ApplicationSet, and returns the progress of the rollout.
+func (r *ApplicationSetReconciler) rollOutInCluster(ctx context.Context, applicationSet argoprojiov1alpha1.ApplicationSet, desiredApplications []argov1alpha1.Application) (*argoprojiov1alpha1.ApplicationSetRolloutStatus, error) {
+	current, err := r.getCurrentApplications(ctx, applicationSet)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []argoprojiov1alpha1.ApplicationSetRolloutStep
+	if applicationSet.Spec.Strategy.RollingUpdate != nil {
+		steps = applicationSet.Spec.Strategy.RollingUpdate.Steps
+	}
+
+	// The fields managed outside of the ApplicationSet don't make the Applications outdated
+	live := make(map[string]*argov1alpha1.Application, len(current))
+	for i := range current {
+		live[current[i].Name] = &current[i]
+	}
+	merged := make([]argov1alpha1.Application, 0, len(desiredApplications))
+	for i := range desiredApplications {
+		app := &desiredApplications[i]
+		if found, exists := live[app.Name]; exists {
+			if app, err = utils.MergeLiveApplication(&applicationSet, app, found); err != nil {
+				return nil, err
+			}
+		}
+		merged = append(merged, *app)
+	}
+
+	rolloutApps, status, err := planRollout(steps, merged, current)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, r.createOrUpdateInCluster(ctx, applicationSet, rolloutApps)
+}
+
+// planRollout returns the desired Applications to create or update now: the outdated Applications of the first step
+// that is not complete, within the maxUnavailable of the step. A step is complete once each of its Applications is
+// up to date, Synced and Healthy.
+func planRollout(steps []argoprojiov1alpha1.ApplicationSetRolloutStep, desiredApplications, currentApplications []argov1alpha1.Application) ([]argov1alpha1.Application, *argoprojiov1alpha1.ApplicationSetRolloutStatus, error) {
+	selectors := make([]labels.Selector, 0, len(steps))
+	for i := range steps {
+		selector, err := metav1.LabelSelectorAsSelector(&steps[i].Selector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid selector in rollout step %d: %w", i+1, err)
+		}
+		selectors = append(selectors, selector)
+	}
+
+	// The last group holds the Applications not selected by any step
+	groups := make([][]argov1alpha1.Application, len(steps)+1)
+	for _, app := range desiredApplications {
+		step := len(steps)
+		for i, selector := range selectors {
+			if selector.Matches(labels.Set(app.Labels)) {
+				step = i
+				break
+			}
+		}
+		groups[step] = append(groups[step], app)
+	}
+	if len(groups[len(steps)]) == 0 {
+		groups = groups[:len(steps)]
+	}
+
+	current := make(map[string]argov1alpha1.Application, len(currentApplications))
+	for _, app := range currentApplications {
+		current[app.Name] = app
+	}
+
+	var res []argov1alpha1.Application
+	status := &argoprojiov1alpha1.ApplicationSetRolloutStatus{Steps: len(groups)}
+
+	for i, group := range groups {
+		var outdated []argov1alpha1.Application
+		unavailable := 0
+		for _, app := range group {
+			found, exists := current[app.Name]
+			if !exists || !reflect.DeepEqual(found.Spec, app.Spec) {
+				outdated = append(outdated, app)
+			} else if !isApplicationAvailable(found) {
+				unavailable++
+			}
+		}
+
+		// The Applications of the steps following the current one wait for it to complete
+		if status.CurrentStep != 0 {
+			for _, app := range outdated {
+				status.PendingApplications = append(status.PendingApplications, app.Name)
+			}
+			continue
+		}
+
+		if len(outdated) == 0 && unavailable == 0 {
+			continue
+		}
+		status.CurrentStep = i + 1
+
+		var maxUnavailable *intstr.IntOrString
+		if i < len(steps) {
+			maxUnavailable = steps[i].MaxUnavailable
+		}
+		allowed, err := getMaxUnavailable(maxUnavailable, len(group))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid maxUnavailable in rollout step %d: %w", i+1, err)
+		}
+		allowed -= unavailable
+
+		sort.Slice(outdated, func(i, j int) bool {
+			return outdated[i].Name < outdated[j].Name
+		})
+		for j, app := range outdated {
+			if j < allowed {
+				res = append(res, app)
+			} else {
+				status.PendingApplications = append(status.PendingApplications, app.Name)
+			}
+		}
+	}
+
+	sort.Strings(status.PendingApplications)
+
+	return res, status, nil
+}
+
+// getMaxUnavailable returns the number of Applications of a step of the given size that can be unavailable at the
+// same time: all of them by default, and at least one.
+func getMaxUnavailable(maxUnavailable *intstr.IntOrString, size int) (int, error) {
+	if maxUnavailable == nil {
+		return size, nil
+	}
+
+	res, err := intstr.GetValueFromIntOrPercent(maxUnavailable, size, false)
+	if err != nil {
+		return 0, err
+	}
+	if res < 1 {
+		res = 1
+	}
+
+	return res, nil
+}
+
+// isApplicationAvailable returns whether Argo CD reports the Application as Synced and Healthy, for its current
+// source: right after an update, the status still describes the previous source until the Application is refreshed.
+func isApplicationAvailable(app argov1alpha1.Application) bool {
+	return app.Status.Sync.Status == argov1alpha1.SyncStatusCodeSynced &&
+		app.Status.Health.Status == health.HealthStatusHealthy &&
+		reflect.DeepEqual(app.Status.Sync.ComparedTo.Source, app.Spec.Source)
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestPlanRollout(t *testing.T) {
+	app := func(name, env, revision string) argov1alpha1.Application {
+		return argov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": env}},
+			Spec: argov1alpha1.ApplicationSpec{
+				Source: argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: revision},
+			},
+		}
+	}
+	available := func(app argov1alpha1.Application) argov1alpha1.Application {
+		app.Status.Sync.Status = argov1alpha1.SyncStatusCodeSynced
+		app.Status.Sync.ComparedTo.Source = app.Spec.Source
+		app.Status.Health.Status = health.HealthStatusHealthy
+		return app
+	}
+	names := func(apps []argov1alpha1.Application) []string {
+		res := []string{}
+		for _, app := range apps {
+			res = append(res, app.Name)
+		}
+		return res
+	}
+
+	maxUnavailable := intstr.FromInt(1)
+	steps := []argoprojiov1alpha1.ApplicationSetRolloutStep{
+		{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}}},
+		{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}, MaxUnavailable: &maxUnavailable},
+	}
+
+	desired := []argov1alpha1.Application{
+		app("prod-b", "prod", "v2"),
+		app("dev", "dev", "v2"),
+		app("prod-a", "prod", "v2"),
+		app("other", "test", "v2"),
+	}
+
+	for _, c := range []struct {
+		name            string
+		current         []argov1alpha1.Application
+		expectedApps    []string
+		expectedStatus  argoprojiov1alpha1.ApplicationSetRolloutStatus
+		expectedPending []string
+	}{
+		{
+			name:           "new applications are created step by step",
+			current:        nil,
+			expectedApps:   []string{"dev"},
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 1, Steps: 3, PendingApplications: []string{"other", "prod-a", "prod-b"}},
+		},
+		{
+			name: "a step waits for the previous one to be healthy",
+			current: []argov1alpha1.Application{
+				app("dev", "dev", "v2"),
+				available(app("prod-a", "prod", "v1")),
+				available(app("prod-b", "prod", "v1")),
+				available(app("other", "test", "v1")),
+			},
+			expectedApps:   nil,
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 1, Steps: 3, PendingApplications: []string{"other", "prod-a", "prod-b"}},
+		},
+		{
+			name: "a step waits for Argo CD to compare the updated source",
+			current: []argov1alpha1.Application{
+				func() argov1alpha1.Application {
+					res := available(app("dev", "dev", "v1"))
+					res.Spec = app("dev", "dev", "v2").Spec
+					return res
+				}(),
+				available(app("prod-a", "prod", "v1")),
+				available(app("prod-b", "prod", "v1")),
+				available(app("other", "test", "v1")),
+			},
+			expectedApps:   nil,
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 1, Steps: 3, PendingApplications: []string{"other", "prod-a", "prod-b"}},
+		},
+		{
+			name: "max unavailable limits the applications updated at once",
+			current: []argov1alpha1.Application{
+				available(app("dev", "dev", "v2")),
+				available(app("prod-a", "prod", "v1")),
+				available(app("prod-b", "prod", "v1")),
+				available(app("other", "test", "v1")),
+			},
+			expectedApps:   []string{"prod-a"},
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 2, Steps: 3, PendingApplications: []string{"other", "prod-b"}},
+		},
+		{
+			name: "unavailable applications count towards max unavailable",
+			current: []argov1alpha1.Application{
+				available(app("dev", "dev", "v2")),
+				app("prod-a", "prod", "v2"),
+				available(app("prod-b", "prod", "v1")),
+				available(app("other", "test", "v1")),
+			},
+			expectedApps:   nil,
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 2, Steps: 3, PendingApplications: []string{"other", "prod-b"}},
+		},
+		{
+			name: "applications not selected by any step are rolled out last",
+			current: []argov1alpha1.Application{
+				available(app("dev", "dev", "v2")),
+				available(app("prod-a", "prod", "v2")),
+				available(app("prod-b", "prod", "v2")),
+				available(app("other", "test", "v1")),
+			},
+			expectedApps:   []string{"other"},
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 3, Steps: 3},
+		},
+		{
+			name: "complete rollout",
+			current: []argov1alpha1.Application{
+				available(app("dev", "dev", "v2")),
+				available(app("prod-a", "prod", "v2")),
+				available(app("prod-b", "prod", "v2")),
+				available(app("other", "test", "v2")),
+			},
+			expectedApps:   nil,
+			expectedStatus: argoprojiov1alpha1.ApplicationSetRolloutStatus{CurrentStep: 0, Steps: 3},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			apps, status, err := planRollout(steps, desired, c.current)
+			assert.NoError(t, err)
+
+			if c.expectedApps == nil {
+				assert.Empty(t, apps)
+			} else {
+				assert.Equal(t, c.expectedApps, names(apps))
+			}
+			assert.Equal(t, c.expectedStatus, *status)
+		})
+	}
+}
+
+func TestPlanRolloutInvalidSelector(t *testing.T) {
+	steps := []argoprojiov1alpha1.ApplicationSetRolloutStep{
+		{Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Unknown"}}}},
+	}
+
+	_, _, err := planRollout(steps, []argov1alpha1.Application{{ObjectMeta: metav1.ObjectMeta{Name: "app"}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestGetMaxUnavailable(t *testing.T) {
+	value := func(v intstr.IntOrString) *intstr.IntOrString {
+		return &v
+	}
+
+	for _, c := range []struct {
+		maxUnavailable *intstr.IntOrString
+		size           int
+		expected       int
+	}{
+		{nil, 5, 5},
+		{value(intstr.FromInt(2)), 5, 2},
+		{value(intstr.FromString("50%")), 5, 2},
+		{value(intstr.FromString("10%")), 5, 1},
+		{value(intstr.FromInt(0)), 5, 1},
+	} {
+		got, err := getMaxUnavailable(c.maxUnavailable, c.size)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, got)
+	}
+
+	_, err := getMaxUnavailable(value(intstr.FromString("half")), 5)
+	assert.Error(t, err)
+}
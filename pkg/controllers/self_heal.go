@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+// isSelfHeal returns whether the manual changes to the Applications of the ApplicationSet are reverted.
+func isSelfHeal(applicationSet *argoprojiov1alpha1.ApplicationSet) bool {
+	syncPolicy := applicationSet.Spec.SyncPolicy
+	return syncPolicy == nil || syncPolicy.SelfHeal == nil || *syncPolicy.SelfHeal
+}
+
+// getDriftedApplications returns the current Applications among the desired ones whose managed fields changed since
+// the controller last applied them. The Applications without the AnnotationApplicationHash, which is only set when
+// SelfHeal is false, are never drifted.
+func (r *ApplicationSetReconciler) getDriftedApplications(ctx context.Context, applicationSet argoprojiov1alpha1.ApplicationSet, desiredApplications []argov1alpha1.Application) ([]argov1alpha1.Application, error) {
+	current, err := r.getCurrentApplications(ctx, applicationSet)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := make(map[string]bool, len(desiredApplications))
+	for _, app := range desiredApplications {
+		desired[app.Name] = true
+	}
+
+	var res []argov1alpha1.Application
+	for i := range current {
+		app := &current[i]
+		applied, found := app.Annotations[argoprojiov1alpha1.AnnotationApplicationHash]
+		if !found || !desired[app.Name] {
+			continue
+		}
+
+		hash, err := utils.HashApplication(&applicationSet, app)
+		if err != nil {
+			return nil, err
+		}
+		if hash != applied {
+			res = append(res, *app)
+		}
+	}
+
+	return res, nil
+}
+
+// getApplicationNames returns the names of the Applications, sorted.
+func getApplicationNames(applications []argov1alpha1.Application) []string {
+	if len(applications) == 0 {
+		return nil
+	}
+
+	res := make([]string, 0, len(applications))
+	for _, app := range applications {
+		res = append(res, app.Name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ignoreOwnedApplicationStatusUpdates filters out the updates of an owned Application that don't change the fields
+// the ApplicationSet reads: the fields it manages, and the sync and health status, which are reported in its status
+// and drive the RollingUpdate strategy. Argo CD updates the rest of the status on every refresh of the Application.
+var ignoreOwnedApplicationStatusUpdates = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldApp, isApp := e.ObjectOld.(*argov1alpha1.Application)
+		if !isApp {
+			return true
+		}
+		newApp, isApp := e.ObjectNew.(*argov1alpha1.Application)
+		if !isApp {
+			return true
+		}
+
+		return !reflect.DeepEqual(oldApp.Spec, newApp.Spec) ||
+			!reflect.DeepEqual(oldApp.Labels, newApp.Labels) ||
+			!reflect.DeepEqual(oldApp.Annotations, newApp.Annotations) ||
+			!reflect.DeepEqual(oldApp.OwnerReferences, newApp.OwnerReferences) ||
+			(oldApp.DeletionTimestamp == nil) != (newApp.DeletionTimestamp == nil) ||
+			oldApp.Status.Sync.Status != newApp.Status.Sync.Status ||
+			!reflect.DeepEqual(oldApp.Status.Sync.ComparedTo.Source, newApp.Status.Sync.ComparedTo.Source) ||
+			oldApp.Status.Health.Status != newApp.Status.Health.Status
+	},
+}
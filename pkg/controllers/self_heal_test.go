@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestIsSelfHeal(t *testing.T) {
+	selfHeal := func(value bool) *bool {
+		return &value
+	}
+
+	assert.True(t, isSelfHeal(&argoprojiov1alpha1.ApplicationSet{}))
+	assert.True(t, isSelfHeal(&argoprojiov1alpha1.ApplicationSet{Spec: argoprojiov1alpha1.ApplicationSetSpec{
+		SyncPolicy: &argoprojiov1alpha1.ApplicationSetSyncPolicy{},
+	}}))
+	assert.True(t, isSelfHeal(&argoprojiov1alpha1.ApplicationSet{Spec: argoprojiov1alpha1.ApplicationSetSpec{
+		SyncPolicy: &argoprojiov1alpha1.ApplicationSetSyncPolicy{SelfHeal: selfHeal(true)},
+	}}))
+	assert.False(t, isSelfHeal(&argoprojiov1alpha1.ApplicationSet{Spec: argoprojiov1alpha1.ApplicationSetSpec{
+		SyncPolicy: &argoprojiov1alpha1.ApplicationSetSyncPolicy{SelfHeal: selfHeal(false)},
+	}}))
+}
+
+func TestGetDriftedApplications(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = argov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	selfHeal := false
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			SyncPolicy:      &argoprojiov1alpha1.ApplicationSetSyncPolicy{SelfHeal: &selfHeal},
+			PreservedFields: &argoprojiov1alpha1.ApplicationPreservedFields{Labels: []string{"team"}},
+		},
+	}
+
+	app := func(name string) argov1alpha1.Application {
+		return argov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": "prod"}},
+			Spec:       argov1alpha1.ApplicationSpec{Project: "default"},
+		}
+	}
+	desired := []argov1alpha1.Application{app("unchanged"), app("spec-changed"), app("preserved-label-changed"), app("not-desired")}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&appSet).Build()
+
+	r := ApplicationSetReconciler{
+		Client:   client,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(len(desired)),
+	}
+
+	err = r.createOrUpdateInCluster(context.TODO(), appSet, desired)
+	assert.Nil(t, err)
+
+	change := func(name string, f func(app *argov1alpha1.Application)) {
+		var live argov1alpha1.Application
+		err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "namespace"}, &live)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, live.Annotations[argoprojiov1alpha1.AnnotationApplicationHash])
+		f(&live)
+		err = client.Update(context.TODO(), &live)
+		assert.Nil(t, err)
+	}
+	change("spec-changed", func(app *argov1alpha1.Application) {
+		app.Spec.Project = "other"
+	})
+	change("preserved-label-changed", func(app *argov1alpha1.Application) {
+		app.Labels["team"] = "platform"
+	})
+	change("not-desired", func(app *argov1alpha1.Application) {
+		app.Spec.Project = "other"
+	})
+
+	drifted, err := r.getDriftedApplications(context.TODO(), appSet, desired[:3])
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"spec-changed"}, getApplicationNames(drifted))
+}
+
+func TestIgnoreOwnedApplicationStatusUpdates(t *testing.T) {
+	app := argov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{"env": "prod"}},
+		Spec:       argov1alpha1.ApplicationSpec{Project: "default"},
+		Status: argov1alpha1.ApplicationStatus{
+			Sync:   argov1alpha1.SyncStatus{Status: argov1alpha1.SyncStatusCodeSynced},
+			Health: argov1alpha1.HealthStatus{Status: "Healthy"},
+		},
+	}
+
+	for _, c := range []struct {
+		name     string
+		change   func(app *argov1alpha1.Application)
+		expected bool
+	}{
+		{"spec", func(app *argov1alpha1.Application) { app.Spec.Project = "other" }, true},
+		{"labels", func(app *argov1alpha1.Application) { app.Labels["env"] = "dev" }, true},
+		{"annotations", func(app *argov1alpha1.Application) { app.Annotations = map[string]string{"key": "value"} }, true},
+		{"sync status", func(app *argov1alpha1.Application) { app.Status.Sync.Status = argov1alpha1.SyncStatusCodeOutOfSync }, true},
+		{"health status", func(app *argov1alpha1.Application) { app.Status.Health.Status = "Degraded" }, true},
+		{"reconciled at", func(app *argov1alpha1.Application) {
+			now := metav1.Now()
+			app.Status.ReconciledAt = &now
+		}, false},
+		{"health message", func(app *argov1alpha1.Application) { app.Status.Health.Message = "message" }, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			newApp := app.DeepCopy()
+			c.change(newApp)
+
+			assert.Equal(t, c.expected, ignoreOwnedApplicationStatusUpdates.Update(event.UpdateEvent{ObjectOld: &app, ObjectNew: newApp}))
+		})
+	}
+}
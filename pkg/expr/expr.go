@@ -0,0 +1,131 @@
+// Package expr implements the expressions used by the generator filters, which are evaluated by
+// https://github.com/antonmedv/expr: see its language definition for the operators and the built-in functions, e.g.
+// {{name}} matches "sales-.*" or len({{regions}}) > 1.
+//
+// An expression is evaluated against a parameter set, and references its parameters with the same syntax as the
+// templates: {{cluster.name}}. Nested keys are looked up both as flat parameter names ("cluster.name") and through
+// nested maps and lists (cluster -> name). A parameter that doesn't exist evaluates to nil.
+//
+// Within list literals, unquoted words that aren't keywords are strings, so that lists of values can be written as
+// [staging, prod]. Elsewhere they are rejected, since env == prod is most likely missing the braces of {{env}}.
+//
+// The comparison operators (==, !=, <, <=, >, >=) convert strings, since the string parameters produced by most
+// generators would otherwise never match: a string is compared as a number to a number, as a boolean to a boolean,
+// and two strings holding numbers are ordered as numbers. {{cluster.enabled}} == true matches the parameter "true",
+// and {{replicas}} < {{maxReplicas}} compares "9" and "10" as numbers.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/file"
+	"github.com/antonmedv/expr/vm"
+)
+
+// Program is a compiled expression, which can be evaluated against any number of parameter sets.
+type Program struct {
+	source  string
+	program *vm.Program
+}
+
+// Compile parses an expression. Syntax errors, including invalid regular expressions literals, are reported here
+// rather than on evaluation.
+func Compile(expression string) (*Program, error) {
+	rewritten, err := rewrite(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []expr.Option{expr.Env(environment(nil))}
+	for operator, function := range comparisonFunctions {
+		options = append(options, expr.Operator(operator, function))
+	}
+	program, err := expr.Compile(rewritten, options...)
+	if err != nil {
+		return nil, withoutSnippet(err)
+	}
+
+	return &Program{source: expression, program: program}, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
+
+// Match evaluates the expression against the parameters. The expression must evaluate to a boolean, or to a string
+// holding a boolean, e.g. {{cluster.enabled}}.
+func (p *Program) Match(params map[string]interface{}) (bool, error) {
+	value, err := expr.Run(p.program, environment(params))
+	if err != nil {
+		return false, withoutSnippet(err)
+	}
+
+	match, ok := toBool(value)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %s, expected a boolean", describe(value))
+	}
+
+	return match, nil
+}
+
+// environment returns the variables of the expressions evaluated against the parameters: the function looking up
+// the parameters, which the references are rewritten to, and the comparison operators.
+func environment(params map[string]interface{}) map[string]interface{} {
+	res := map[string]interface{}{
+		paramFunction: func(name string) interface{} {
+			value, _ := lookup(params, name)
+			return value
+		},
+	}
+	for operator, function := range comparisonFunctions {
+		res[function] = comparisons[operator]
+	}
+	return res
+}
+
+// withoutSnippet returns the message of the errors of the expr language, without the snippet of the rewritten
+// expression, whose parameter references would be confusing.
+func withoutSnippet(err error) error {
+	if fileErr, ok := err.(*file.Error); ok {
+		return errors.New(fileErr.Message)
+	}
+	return err
+}
+
+// lookup returns the parameter with the given dotted name, either as a flat parameter name or through nested maps
+// and lists. Flat names win, and the longest flat prefix is tried first at each level, so that flattened and nested
+// parameters can be mixed, e.g. "values.env" within a "values" map.
+func lookup(value interface{}, name string) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if item, found := v[name]; found {
+			return item, true
+		}
+		for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+			if item, found := v[name[:i]]; found {
+				if res, found := lookup(item, name[i+1:]); found {
+					return res, true
+				}
+			}
+		}
+	case []interface{}:
+		head, tail := name, ""
+		if i := strings.Index(name, "."); i >= 0 {
+			head, tail = name[:i], name[i+1:]
+		}
+		index, err := strconv.Atoi(head)
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		if tail == "" {
+			return v[index], true
+		}
+		return lookup(v[index], tail)
+	}
+	return nil, false
+}
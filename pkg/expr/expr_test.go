@@ -0,0 +1,141 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	params := map[string]interface{}{
+		"name":                        "sales-prod",
+		"server":                      "https://kubernetes.default.svc",
+		"metadata.labels.environment": "prod",
+		"cluster.enabled":             "true",
+		"cluster.replicas":            "3",
+		"n":                           "9",
+		"cluster.n":                   "10",
+		"values.foo":                  "bar",
+		"values": map[string]interface{}{
+			"region": "eu",
+		},
+		"app": map[string]interface{}{
+			"enabled":  true,
+			"replicas": float64(5),
+			"regions":  []interface{}{"eu", "us"},
+			"owner":    map[string]interface{}{"team": "payments"},
+		},
+	}
+
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{`{{name}} matches "sales-.*"`, true},
+		{`{{name}} matches '^prod'`, false},
+		{`{{metadata.labels.environment}} in [staging, prod]`, true},
+		{`{{metadata.labels.environment}} not in ["staging", "prod"]`, false},
+		{`{{cluster.enabled}} == true`, true},
+		{`{{cluster.enabled}}`, true},
+		{`{{cluster.replicas}} >= 3`, true},
+		{`{{cluster.replicas}} > 3`, false},
+		{`{{app.enabled}} && {{app.replicas}} == 5`, true},
+		{`{{app.replicas}} < 10 and {{app.replicas}} != 4`, true},
+		{`"us" in {{app.regions}}`, true},
+		{`{{app.regions.1}} == "us"`, true},
+		{`{{app.owner.team}} == "payments"`, true},
+		{`{{values.foo}} == "bar" and {{values.region}} == "eu"`, true},
+		{`"team" in {{app.owner}}`, true},
+		{`{{server}} startsWith "https://" && {{server}} endsWith ".svc"`, true},
+		{`{{server}} contains "kubernetes"`, true},
+		{`{{missing}} == nil`, true},
+		{`{{missing}} != nil and {{missing}} matches ".*"`, false},
+		{`not ({{name}} == "sales-prod")`, false},
+		{`!{{app.enabled}} || {{name}} == "sales-prod"`, true},
+		{`{{name}} == "other" or ({{name}} == "sales-prod" and {{app.enabled}})`, true},
+		{`{{ name }} < "t"`, true},
+		{`[eu, us] == {{app.regions}}`, true},
+		{`{{metadata.labels.environment}} in [us-east-1, prod.v2, prod]`, true},
+		{`{{n}} < {{cluster.n}}`, true},
+		{`{{n}} > "10"`, false},
+		{`{{n}} >= 9.0`, true},
+		{`{{name}} == "sales-prod" and {{cluster.replicas}} != "3.0"`, true},
+		{`{{cluster.replicas}} == 3.0`, true},
+		{`"1.10" > "1.9"`, false},
+		{`len({{app.regions}}) == 2`, true},
+		{`all({{app.regions}}, {# in [eu, us, ap]})`, true},
+		{`any({{app.regions}}, {# startsWith "a"})`, false},
+		{`{{app.replicas}} * 2 > 8`, true},
+		{`({{app.enabled}} ? {{app.owner.team}} : "none") == "payments"`, true},
+		{`{{app.regions}}[0] == "eu"`, true},
+	}
+
+	for _, testCase := range testCases {
+		program, err := Compile(testCase.expression)
+		if !assert.NoError(t, err, testCase.expression) {
+			continue
+		}
+
+		got, err := program.Match(params)
+		assert.NoError(t, err, testCase.expression)
+		assert.Equal(t, testCase.expected, got, testCase.expression)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	testCases := []string{
+		``,
+		`{{name}} ==`,
+		`{{name}} = "a"`,
+		`{{name}} matches "[a-"`,
+		`{{name}} matches 3`,
+		`{{name`,
+		`{{}} == "a"`,
+		`"unterminated`,
+		`({{name}} == "a"`,
+		`[a, b`,
+		`{{name}} == "a" "b"`,
+		`{{name}} in and`,
+		`{{name}} == unknown("a")`,
+		`{{name}} in 3`,
+		`{{name}} # "a"`,
+		`env == prod`,
+		`{{env}} == prod`,
+		`{{env}} in [staging, (prod)] or {{env}} == prod`,
+	}
+
+	for _, expression := range testCases {
+		_, err := Compile(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	params := map[string]interface{}{
+		"name":     "app",
+		"replicas": float64(3),
+		"labels":   map[string]interface{}{"env": "prod"},
+	}
+
+	testCases := []string{
+		`{{name}}`,
+		`{{name}} and true`,
+		`not {{replicas}}`,
+		`{{replicas}} > "many"`,
+		`{{labels}} < 3`,
+		`{{labels}} startsWith "a"`,
+		`{{name}} matches {{labels}}`,
+		`{{missing}} matches ".*"`,
+		`{{labels}} contains "a"`,
+	}
+
+	for _, expression := range testCases {
+		program, err := Compile(expression)
+		if !assert.NoError(t, err, expression) {
+			continue
+		}
+
+		_, err = program.Match(params)
+		assert.Error(t, err, expression)
+	}
+}
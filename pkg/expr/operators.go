@@ -0,0 +1,143 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// comparisonFunctions are the names of the functions of the environment overloading the comparison operators.
+var comparisonFunctions = map[string]string{
+	"==": "_equal",
+	"!=": "_notEqual",
+	"<":  "_less",
+	"<=": "_lessOrEqual",
+	">":  "_greater",
+	">=": "_greaterOrEqual",
+}
+
+// comparisons are the comparison operators, converting the strings compared to numbers or booleans.
+var comparisons = map[string]func(a, b interface{}) bool{
+	"==": equal,
+	"!=": func(a, b interface{}) bool { return !equal(a, b) },
+	"<":  func(a, b interface{}) bool { return order("<", a, b) < 0 },
+	"<=": func(a, b interface{}) bool { return order("<=", a, b) <= 0 },
+	">":  func(a, b interface{}) bool { return order(">", a, b) > 0 },
+	">=": func(a, b interface{}) bool { return order(">=", a, b) >= 0 },
+}
+
+// equal compares two values, converting strings when compared to numbers or booleans. Two strings are equal only if
+// they are the same, e.g. "1.0" isn't "1".
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString && bIsString {
+		return as == bs
+	}
+
+	_, aIsBool := a.(bool)
+	_, bIsBool := b.(bool)
+	if aIsBool || bIsBool {
+		ab, aok := toBool(a)
+		bb, bok := toBool(b)
+		return aok && bok && ab == bb
+	}
+
+	an, aIsNumber := toNumber(a)
+	bn, bIsNumber := toNumber(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && an == bn
+	}
+
+	// The constant lists of the expressions are typed, e.g. []string, unlike the lists of the parameters
+	al, bl := reflect.ValueOf(a), reflect.ValueOf(b)
+	if al.Kind() == reflect.Slice && bl.Kind() == reflect.Slice {
+		if al.Len() != bl.Len() {
+			return false
+		}
+		for i := 0; i < al.Len(); i++ {
+			if !equal(al.Index(i).Interface(), bl.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// order returns -1, 0 or 1 as a is less than, equal to or greater than b. Numbers, and strings holding numbers, are
+// compared as numbers, and other strings alphabetically. Other values can't be ordered, which fails the evaluation
+// of the expression.
+func order(operator string, a, b interface{}) int {
+	an, aIsNumber := toNumber(a)
+	bn, bIsNumber := toNumber(b)
+	if aIsNumber && bIsNumber {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	}
+
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString && bIsString {
+		return strings.Compare(as, bs)
+	}
+
+	panic(fmt.Sprintf("%s can't compare %s and %s", operator, describe(a), describe(b)))
+}
+
+// toNumber returns the value of any Go number, or of a string holding a number, as a float64.
+func toNumber(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return number, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// toBool returns the value of a boolean, or of a string holding a boolean.
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+// describe formats a value for error messages.
+func describe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "a map"
+	}
+	return fmt.Sprintf("%T %v", value, value)
+}
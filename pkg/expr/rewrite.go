@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// paramFunction is the name of the function of the environment returning a parameter by name.
+const paramFunction = "_param"
+
+// keywords are the words of the language that aren't quoted within list literals.
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true,
+	"matches": true, "contains": true, "startsWith": true, "endsWith": true,
+	"true": true, "false": true, "nil": true,
+}
+
+// rewrite returns the expression in the syntax of the expr language: the parameter references, {{name}}, are
+// replaced by calls to the parameter function, and the unquoted words of the list literals, e.g. [staging, prod],
+// are quoted. The rest of the expression is left as is, for the expr language to parse.
+func rewrite(expression string) (string, error) {
+	var res strings.Builder
+	runes := []rune(expression)
+	// brackets are the brackets opened so far, with 'l' for the list literals and '[' for the indexes
+	var brackets []rune
+	// afterValue is whether the last token is a value, after which a bracket is an index rather than a list literal
+	afterValue := false
+
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+
+		switch {
+		case strings.HasPrefix(string(runes[pos:]), "{{"):
+			rest := string(runes[pos:])
+			end := strings.Index(rest, "}}")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated parameter reference at position %d", pos)
+			}
+			name := strings.TrimSpace(rest[2:end])
+			if name == "" {
+				return "", fmt.Errorf("empty parameter reference at position %d", pos)
+			}
+			fmt.Fprintf(&res, "%s(%s)", paramFunction, strconv.Quote(name))
+			pos += len([]rune(rest[:end+2]))
+			afterValue = true
+
+		case r == '"' || r == '\'':
+			end := pos + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				// The expr language reports the unterminated string
+				end = len(runes) - 1
+			}
+			res.WriteString(string(runes[pos : end+1]))
+			pos = end + 1
+			afterValue = true
+
+		case isWordRune(r) && !strings.ContainsRune("-./", r):
+			end := pos + 1
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			word := string(runes[pos:end])
+			if len(brackets) > 0 && brackets[len(brackets)-1] == 'l' && isUnquotedString(word, previousRune(runes, pos), nextRune(runes, end)) {
+				res.WriteString(strconv.Quote(word))
+			} else {
+				res.WriteString(word)
+			}
+			pos = end
+			afterValue = !keywords[word] || word == "true" || word == "false" || word == "nil"
+
+		default:
+			switch r {
+			case '[':
+				if afterValue {
+					brackets = append(brackets, '[')
+				} else {
+					brackets = append(brackets, 'l')
+				}
+			case '(', '{':
+				brackets = append(brackets, r)
+			case ']', ')', '}':
+				if len(brackets) > 0 {
+					brackets = brackets[:len(brackets)-1]
+				}
+			}
+			res.WriteRune(r)
+			pos++
+			if !unicode.IsSpace(r) {
+				afterValue = strings.ContainsRune("])}#", r)
+			}
+		}
+	}
+
+	return res.String(), nil
+}
+
+// isUnquotedString returns whether a word of a list literal is a string: words that aren't keywords, numbers,
+// function calls or properties, e.g. prod or us-east-1.
+func isUnquotedString(word string, previous, next rune) bool {
+	if keywords[word] || previous == '.' || previous == '#' || next == '(' {
+		return false
+	}
+	_, err := strconv.ParseFloat(word, 64)
+	return err != nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-./", r)
+}
+
+// previousRune returns the last rune before pos which isn't a space, or 0.
+func previousRune(runes []rune, pos int) rune {
+	for i := pos - 1; i >= 0; i-- {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return 0
+}
+
+// nextRune returns the first rune from pos which isn't a space, or 0.
+func nextRune(runes []rune, pos int) rune {
+	for i := pos; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return 0
+}
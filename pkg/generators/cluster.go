@@ -41,8 +41,12 @@ func (g *ClusterGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.Appli
 	return &appSetGenerator.Clusters.Template
 }
 
+func (g *ClusterGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.Clusters.Filters
+}
+
 func (g *ClusterGenerator) GenerateParams(
-	appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error) {
+	appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
 
 	if appSetGenerator == nil {
 		return nil, EmptyAppSetGeneratorError
@@ -67,9 +71,9 @@ func (g *ClusterGenerator) GenerateParams(
 	log.Debug("clusters matching labels", "count", len(clusterSecretList.Items))
 
 	// For each matching cluster secret
-	res := make([]map[string]string, len(clusterSecretList.Items))
+	res := make([]map[string]interface{}, len(clusterSecretList.Items))
 	for i, cluster := range clusterSecretList.Items {
-		params := make(map[string]string, len(appSetGenerator.Clusters.Values)+len(cluster.ObjectMeta.Annotations)+len(cluster.ObjectMeta.Labels)+2)
+		params := make(map[string]interface{}, len(appSetGenerator.Clusters.Values)+len(cluster.ObjectMeta.Annotations)+len(cluster.ObjectMeta.Labels)+2)
 		params["name"] = string(cluster.Data["name"])
 		params["server"] = string(cluster.Data["server"])
 		for key, value := range cluster.ObjectMeta.Annotations {
@@ -83,14 +83,14 @@ func TestGenerateParams(t *testing.T) {
 	testCases := []struct {
 		selector      metav1.LabelSelector
 		values        map[string]string
-		expected      []map[string]string
+		expected      []map[string]interface{}
 		clientError   bool
 		expectedError error
 	}{
 		{
 			metav1.LabelSelector{},
 			nil,
-			[]map[string]string{
+			[]map[string]interface{}{
 				{"name": "cHJvZHVjdGlvbi0wMQ==", "server": "https://production-01.example.com", "metadata.labels.environment": "production", "metadata.labels.org": "bar", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "production"},
 				{"name": "c3RhZ2luZy0wMQ==", "server": "https://staging-01.example.com", "metadata.labels.environment": "staging", "metadata.labels.org": "foo", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "staging"},
 			},
@@ -106,7 +106,7 @@ func TestGenerateParams(t *testing.T) {
 			map[string]string{
 				"foo": "bar",
 			},
-			[]map[string]string{
+			[]map[string]interface{}{
 				{"values.foo": "bar", "name": "cHJvZHVjdGlvbi0wMQ==", "server": "https://production-01.example.com", "metadata.labels.environment": "production", "metadata.labels.org": "bar", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "production"},
 			},
 			false,
@@ -128,7 +128,7 @@ func TestGenerateParams(t *testing.T) {
 			map[string]string{
 				"foo": "bar",
 			},
-			[]map[string]string{
+			[]map[string]interface{}{
 				{"values.foo": "bar", "name": "c3RhZ2luZy0wMQ==", "server": "https://staging-01.example.com", "metadata.labels.environment": "staging", "metadata.labels.org": "foo", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "staging"},
 				{"values.foo": "bar", "name": "cHJvZHVjdGlvbi0wMQ==", "server": "https://production-01.example.com", "metadata.labels.environment": "production", "metadata.labels.org": "bar", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "production"},
 			},
@@ -154,7 +154,7 @@ func TestGenerateParams(t *testing.T) {
 			map[string]string{
 				"name": "baz",
 			},
-			[]map[string]string{
+			[]map[string]interface{}{
 				{"values.name": "baz", "name": "c3RhZ2luZy0wMQ==", "server": "https://staging-01.example.com", "metadata.labels.environment": "staging", "metadata.labels.org": "foo", "metadata.labels.argocd.argoproj.io/secret-type": "cluster", "metadata.annotations.foo.argoproj.io": "staging"},
 			},
 			false,
@@ -183,7 +183,7 @@ func TestGenerateParams(t *testing.T) {
 				Selector: testCase.selector,
 				Values:   testCase.values,
 			},
-		})
+		}, &argoprojiov1alpha1.ApplicationSet{})
 
 		if testCase.expectedError != nil {
 			assert.Error(t, testCase.expectedError, err)
@@ -0,0 +1,213 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// Keys of the ConfigMap describing the resource read by a clusterDecisionResource generator
+const (
+	DuckTypeAPIVersionKey    = "apiVersion"
+	DuckTypeResourceKey      = "resource"
+	DuckTypeStatusListKeyKey = "statusListKey"
+	DuckTypeMatchKeyKey      = "matchKey"
+)
+
+var _ Generator = (*DuckTypeGenerator)(nil)
+
+// DuckTypeResource is the resource read by a clusterDecisionResource generator, as described by its ConfigMap.
+type DuckTypeResource struct {
+	GroupVersionResource schema.GroupVersionResource
+	// StatusListKey is the key of the list of decisions within the status of the resource
+	StatusListKey string
+	// MatchKey is the key of the cluster name within each decision
+	MatchKey string
+}
+
+// GetDuckTypeResource reads the ConfigMap describing the resource of a clusterDecisionResource generator.
+func GetDuckTypeResource(ctx context.Context, c client.Client, configMapName string, namespace string) (*DuckTypeResource, error) {
+	if configMapName == "" {
+		return nil, fmt.Errorf("configMapRef is required for the clusterDecisionResource generator")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: namespace}, configMap); err != nil {
+		return nil, fmt.Errorf("error reading ConfigMap %s/%s: %v", namespace, configMapName, err)
+	}
+
+	for _, key := range []string{DuckTypeAPIVersionKey, DuckTypeResourceKey, DuckTypeStatusListKeyKey, DuckTypeMatchKeyKey} {
+		if configMap.Data[key] == "" {
+			return nil, fmt.Errorf("ConfigMap %s/%s is missing the %q key", namespace, configMapName, key)
+		}
+	}
+
+	gv, err := schema.ParseGroupVersion(configMap.Data[DuckTypeAPIVersionKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion in ConfigMap %s/%s: %v", namespace, configMapName, err)
+	}
+
+	return &DuckTypeResource{
+		GroupVersionResource: gv.WithResource(configMap.Data[DuckTypeResourceKey]),
+		StatusListKey:        configMap.Data[DuckTypeStatusListKeyKey],
+		MatchKey:             configMap.Data[DuckTypeMatchKeyKey],
+	}, nil
+}
+
+// DuckTypeGenerator generates Applications for the clusters chosen by an external placement engine, as recorded in
+// the status of a duck-typed resource.
+type DuckTypeGenerator struct {
+	client.Client
+	dynClient dynamic.Interface
+	// namespace is the namespace of the ConfigMaps, of the decision resources and of the cluster Secrets
+	namespace string
+}
+
+func NewDuckTypeGenerator(c client.Client, dynClient dynamic.Interface, namespace string) Generator {
+	g := &DuckTypeGenerator{
+		Client:    c,
+		dynClient: dynClient,
+		namespace: namespace,
+	}
+	return g
+}
+
+func (g *DuckTypeGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	return time.Duration(appSetGenerator.ClusterDecisionResource.RequeueAfterSeconds) * time.Second
+}
+
+func (g *DuckTypeGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.ClusterDecisionResource.Template
+}
+
+func (g *DuckTypeGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.ClusterDecisionResource.Filters
+}
+
+func (g *DuckTypeGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.ClusterDecisionResource == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	ctx := context.Background()
+	generatorConfig := appSetGenerator.ClusterDecisionResource
+
+	resource, err := GetDuckTypeResource(ctx, g.Client, generatorConfig.ConfigMapRef, g.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resources are read in the namespace watched by the controller, see DuckTypeWatcher, so that their changes
+	// requeue the ApplicationSet
+	decisionResources, err := g.getDecisionResources(ctx, resource, generatorConfig, g.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := g.getClustersByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []map[string]interface{}{}
+	for _, decisionResource := range decisionResources {
+		decisions, found, err := unstructured.NestedSlice(decisionResource.Object, "status", resource.StatusListKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading status.%s of %s %s: %v", resource.StatusListKey, resource.GroupVersionResource.Resource, decisionResource.GetName(), err)
+		}
+		if !found {
+			log.WithField("resource", decisionResource.GetName()).Infof("status.%s not found, no decision made yet", resource.StatusListKey)
+			continue
+		}
+
+		for _, decision := range decisions {
+			decisionMap, ok := decision.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("decision in status.%s of %s %s is not an object", resource.StatusListKey, resource.GroupVersionResource.Resource, decisionResource.GetName())
+			}
+
+			clusterName, ok := decisionMap[resource.MatchKey].(string)
+			if !ok {
+				return nil, fmt.Errorf("decision in status.%s of %s %s has no string %q key", resource.StatusListKey, resource.GroupVersionResource.Resource, decisionResource.GetName(), resource.MatchKey)
+			}
+
+			cluster, found := clusters[clusterName]
+			if !found {
+				log.WithField("cluster", clusterName).Warn("no cluster Secret matches the decision, skipping")
+				continue
+			}
+
+			params := make(map[string]interface{}, len(generatorConfig.Values)+2)
+			params["name"] = string(cluster.Data["name"])
+			params["server"] = string(cluster.Data["server"])
+			for key, value := range generatorConfig.Values {
+				params[fmt.Sprintf("values.%s", key)] = value
+			}
+			log.WithField("cluster", clusterName).Info("matched cluster decision")
+
+			res = append(res, params)
+		}
+	}
+
+	return res, nil
+}
+
+// getDecisionResources returns the resources named or selected by the generator.
+func (g *DuckTypeGenerator) getDecisionResources(ctx context.Context, resource *DuckTypeResource, generatorConfig *argoprojiov1alpha1.DuckTypeGenerator, namespace string) ([]unstructured.Unstructured, error) {
+	resourceClient := g.dynClient.Resource(resource.GroupVersionResource).Namespace(namespace)
+
+	if generatorConfig.Name != "" {
+		obj, err := resourceClient.Get(ctx, generatorConfig.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s %s/%s: %v", resource.GroupVersionResource.Resource, namespace, generatorConfig.Name, err)
+		}
+		return []unstructured.Unstructured{*obj}, nil
+	}
+
+	if len(generatorConfig.LabelSelector.MatchLabels) == 0 && len(generatorConfig.LabelSelector.MatchExpressions) == 0 {
+		return nil, fmt.Errorf("either name or labelSelector must be set for the clusterDecisionResource generator")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&generatorConfig.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := resourceClient.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s in %s: %v", resource.GroupVersionResource.Resource, namespace, err)
+	}
+
+	return list.Items, nil
+}
+
+// getClustersByName returns the cluster Secrets registered with ArgoCD, indexed by cluster name.
+func (g *DuckTypeGenerator) getClustersByName(ctx context.Context) (map[string]corev1.Secret, error) {
+	clusterSecretList := &corev1.SecretList{}
+	if err := g.Client.List(ctx, clusterSecretList, client.InNamespace(g.namespace), client.MatchingLabels{ArgoCDSecretTypeLabel: ArgoCDSecretTypeCluster}); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]corev1.Secret, len(clusterSecretList.Items))
+	for _, cluster := range clusterSecretList.Items {
+		res[string(cluster.Data["name"])] = cluster
+	}
+
+	return res, nil
+}
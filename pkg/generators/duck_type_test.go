@@ -0,0 +1,159 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func duckResource(name string, labels map[string]interface{}, decisions ...string) *unstructured.Unstructured {
+	decisionList := []interface{}{}
+	for _, decision := range decisions {
+		decisionList = append(decisionList, map[string]interface{}{"clusterName": decision, "reason": "placement"})
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "mallard.io/v1",
+			"kind":       "Duck",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "namespace",
+				"labels":    labels,
+			},
+			"status": map[string]interface{}{
+				"decisions": decisionList,
+			},
+		},
+	}
+}
+
+func TestDuckTypeGenerateParams(t *testing.T) {
+	objects := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-configmap", Namespace: "namespace"},
+			Data: map[string]string{
+				"apiVersion":    "mallard.io/v1",
+				"resource":      "ducks",
+				"statusListKey": "decisions",
+				"matchKey":      "clusterName",
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "incomplete-configmap", Namespace: "namespace"},
+			Data: map[string]string{
+				"apiVersion": "mallard.io/v1",
+				"resource":   "ducks",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "staging-01",
+				Namespace: "namespace",
+				Labels:    map[string]string{ArgoCDSecretTypeLabel: ArgoCDSecretTypeCluster},
+			},
+			Data: map[string][]byte{
+				"name":   []byte("staging-01"),
+				"server": []byte("https://staging-01.example.com"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "production-01",
+				Namespace: "namespace",
+				Labels:    map[string]string{ArgoCDSecretTypeLabel: ArgoCDSecretTypeCluster},
+			},
+			Data: map[string][]byte{
+				"name":   []byte("production-01"),
+				"server": []byte("https://production-01.example.com"),
+			},
+		},
+	}
+
+	ducks := []runtime.Object{
+		duckResource("duck-a", map[string]interface{}{"duck": "spotted"}, "staging-01", "unknown-cluster"),
+		duckResource("duck-b", map[string]interface{}{"duck": "spotted"}, "production-01"),
+		duckResource("duck-c", map[string]interface{}{"duck": "plain"}),
+	}
+
+	testCases := []struct {
+		name        string
+		generator   argoprojiov1alpha1.DuckTypeGenerator
+		expected    []map[string]interface{}
+		expectedErr bool
+	}{
+		{
+			name:      "resource by name",
+			generator: argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "my-configmap", Name: "duck-a", Values: map[string]string{"foo": "bar"}},
+			expected: []map[string]interface{}{
+				{"name": "staging-01", "server": "https://staging-01.example.com", "values.foo": "bar"},
+			},
+		},
+		{
+			name: "resources by label selector",
+			generator: argoprojiov1alpha1.DuckTypeGenerator{
+				ConfigMapRef:  "my-configmap",
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"duck": "spotted"}},
+			},
+			expected: []map[string]interface{}{
+				{"name": "staging-01", "server": "https://staging-01.example.com"},
+				{"name": "production-01", "server": "https://production-01.example.com"},
+			},
+		},
+		{
+			name:      "resource without decisions",
+			generator: argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "my-configmap", Name: "duck-c"},
+			expected:  []map[string]interface{}{},
+		},
+		{
+			name:        "missing resource",
+			generator:   argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "my-configmap", Name: "duck-z"},
+			expectedErr: true,
+		},
+		{
+			name:        "neither name nor label selector",
+			generator:   argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "my-configmap"},
+			expectedErr: true,
+		},
+		{
+			name:        "missing configmap",
+			generator:   argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "missing-configmap", Name: "duck-a"},
+			expectedErr: true,
+		},
+		{
+			name:        "incomplete configmap",
+			generator:   argoprojiov1alpha1.DuckTypeGenerator{ConfigMapRef: "incomplete-configmap", Name: "duck-a"},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithObjects(objects...).Build()
+			fakeDynClient := dynfake.NewSimpleDynamicClient(runtime.NewScheme(), ducks...)
+
+			var duckTypeGenerator = NewDuckTypeGenerator(fakeClient, fakeDynClient, "namespace")
+
+			got, err := duckTypeGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				ClusterDecisionResource: &testCaseCopy.generator,
+			}, &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "appset", Namespace: "namespace"}})
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
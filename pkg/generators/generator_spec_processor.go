@@ -0,0 +1,143 @@
+package generators
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/expr"
+	log "github.com/sirupsen/logrus"
+)
+
+var MoreThanOneInnerGeneratorError = errors.New("found more than one generator in a single nested generator entry")
+
+// GetRelevantGenerators returns the generators from allGenerators that are requested by requestedGenerator.
+// The generators are matched by the name of the non-nil ApplicationSetGenerator fields (e.g. "List", "Git").
+func GetRelevantGenerators(requestedGenerator *argoprojiov1alpha1.ApplicationSetGenerator, allGenerators map[string]Generator) []Generator {
+	var res []Generator
+
+	v := reflect.Indirect(reflect.ValueOf(requestedGenerator))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanInterface() {
+			continue
+		}
+
+		if !reflect.ValueOf(field.Interface()).IsNil() {
+			name := v.Type().Field(i).Name
+			g, ok := allGenerators[name]
+			if !ok {
+				log.Warnf("generator %s is not registered", name)
+				continue
+			}
+			res = append(res, g)
+		}
+	}
+
+	return res
+}
+
+// getNestedGenerator returns the single Generator that handles the given nested generator.
+func getNestedGenerator(nestedGenerator argoprojiov1alpha1.ApplicationSetNestedGenerator, supportedGenerators map[string]Generator) (Generator, error) {
+	generators := GetRelevantGenerators(nestedGenerator.ToApplicationSetGenerator(), supportedGenerators)
+
+	if len(generators) == 0 {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if len(generators) > 1 {
+		return nil, MoreThanOneInnerGeneratorError
+	}
+
+	return generators[0], nil
+}
+
+// generateNestedParams generates the parameters of a generator nested within a combination-type generator.
+func generateNestedParams(nestedGenerator argoprojiov1alpha1.ApplicationSetNestedGenerator, supportedGenerators map[string]Generator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+	g, err := getNestedGenerator(nestedGenerator, supportedGenerators)
+	if err != nil {
+		return nil, err
+	}
+
+	appSetGenerator := nestedGenerator.ToApplicationSetGenerator()
+
+	params, err := g.GenerateParams(appSetGenerator, applicationSetInfo)
+	if err != nil {
+		return nil, fmt.Errorf("child generator returned an error on parameter generation: %w", err)
+	}
+
+	params, err = FilterParams(g.GetFilters(appSetGenerator), params)
+	if err != nil {
+		return nil, fmt.Errorf("child generator returned an error on parameter filtering: %w", err)
+	}
+
+	return params, nil
+}
+
+// FilterParams returns the parameter sets matching all the filters, in their original order. Invalid expressions,
+// and expressions that can't be evaluated against a parameter set, are reported as errors rather than ignored, since
+// silently dropping (or keeping) parameter sets could delete (or create) Applications.
+func FilterParams(filters []argoprojiov1alpha1.GeneratorFilter, params []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(filters) == 0 {
+		return params, nil
+	}
+
+	programs := make([]*expr.Program, 0, len(filters))
+	for _, filter := range filters {
+		program, err := expr.Compile(filter.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter expression %q: %v", filter.Expr, err)
+		}
+		programs = append(programs, program)
+	}
+
+	res := make([]map[string]interface{}, 0, len(params))
+	for _, paramSet := range params {
+		matched := true
+		for _, program := range programs {
+			match, err := program.Match(paramSet)
+			if err != nil {
+				return nil, fmt.Errorf("error evaluating filter expression %q: %v", program, err)
+			}
+			if !match {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			res = append(res, paramSet)
+		}
+	}
+
+	return res, nil
+}
+
+const maxDuration time.Duration = 1<<63 - 1
+
+// getNestedRequeueAfter returns the smallest requeue duration requested by any of the nested generators,
+// or NoRequeueAfter if none of them requested one.
+func getNestedRequeueAfter(nestedGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator, supportedGenerators map[string]Generator) time.Duration {
+	res := maxDuration
+	var found bool
+
+	for _, r := range nestedGenerators {
+		base := r.ToApplicationSetGenerator()
+		generators := GetRelevantGenerators(base, supportedGenerators)
+
+		for _, g := range generators {
+			temp := g.GetRequeueAfter(base)
+			if temp < res && temp != NoRequeueAfter {
+				found = true
+				res = temp
+			}
+		}
+	}
+
+	if found {
+		return res
+	}
+
+	return NoRequeueAfter
+}
@@ -1,16 +1,24 @@
 package generators
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"path"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
 	"github.com/argoproj-labs/applicationset/pkg/services"
-	"github.com/jeremywohl/flatten"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
 	log "github.com/sirupsen/logrus"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
 )
 
 var _ Generator = (*GitGenerator)(nil)
@@ -30,11 +38,15 @@ func (g *GitGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.Applicati
 	return &appSetGenerator.Git.Template
 }
 
+func (g *GitGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.Git.Filters
+}
+
 func (g *GitGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
 	return time.Duration(appSetGenerator.Git.RequeueAfterSeconds) * time.Second
 }
 
-func (g *GitGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error) {
+func (g *GitGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
 
 	if appSetGenerator == nil {
 		return nil, EmptyAppSetGeneratorError
@@ -44,14 +56,21 @@ func (g *GitGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.Applic
 		return nil, EmptyAppSetGeneratorError
 	}
 
-	var err error
-	var res []map[string]string
+	if appSetGenerator.Git.Directories == nil && appSetGenerator.Git.Files == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	// Resolve the revision once, so that every listing and file read of the generation reads the same commit
+	revision, err := g.repos.ResolveRevision(context.TODO(), appSetGenerator.Git.RepoURL, appSetGenerator.Git.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []map[string]interface{}
 	if appSetGenerator.Git.Directories != nil {
-		res, err = g.generateParamsForGitDirectories(appSetGenerator)
-	} else if appSetGenerator.Git.Files != nil {
-		res, err = g.generateParamsForGitFiles(appSetGenerator)
+		res, err = g.generateParamsForGitDirectories(appSetGenerator, revision)
 	} else {
-		return nil, EmptyAppSetGeneratorError
+		res, err = g.generateParamsForGitFiles(appSetGenerator, revision, applicationSetInfo.Spec.GoTemplate)
 	}
 	if err != nil {
 		return nil, err
@@ -60,8 +79,18 @@ func (g *GitGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.Applic
 	return res, nil
 }
 
-func (g *GitGenerator) generateParamsForGitDirectories(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error) {
-	allApps, err := g.repos.GetApps(context.TODO(), appSetGenerator.Git.RepoURL, appSetGenerator.Git.Revision)
+func (g *GitGenerator) generateParamsForGitDirectories(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, revision string) ([]map[string]interface{}, error) {
+	var allApps []string
+	var err error
+	switch appSetGenerator.Git.DirectoryDiscovery {
+	case "", argoprojiov1alpha1.GitDirectoryDiscoveryApps:
+		allApps, err = g.repos.GetApps(context.TODO(), appSetGenerator.Git.RepoURL, revision)
+	case argoprojiov1alpha1.GitDirectoryDiscoveryAll:
+		allApps, err = g.repos.GetDirectories(context.TODO(), appSetGenerator.Git.RepoURL, revision)
+	default:
+		return nil, fmt.Errorf("unknown directory discovery %q, expected %s or %s", appSetGenerator.Git.DirectoryDiscovery,
+			argoprojiov1alpha1.GitDirectoryDiscoveryApps, argoprojiov1alpha1.GitDirectoryDiscoveryAll)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +99,7 @@ func (g *GitGenerator) generateParamsForGitDirectories(appSetGenerator *argoproj
 		"allAps":   allApps,
 		"total":    len(allApps),
 		"repoURL":  appSetGenerator.Git.RepoURL,
-		"revision": appSetGenerator.Git.Revision,
+		"revision": revision,
 	}).Info("applications result from the repo service")
 
 	requestedApps := g.filterApps(appSetGenerator.Git.Directories, allApps)
@@ -80,17 +109,24 @@ func (g *GitGenerator) generateParamsForGitDirectories(appSetGenerator *argoproj
 	return res, nil
 }
 
-func (g *GitGenerator) generateParamsForGitFiles(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error) {
+func (g *GitGenerator) generateParamsForGitFiles(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, revision string, useGoTemplate bool) ([]map[string]interface{}, error) {
 
 	// Get all paths that match the requested path string, removing duplicates
 	allPathsMap := make(map[string]bool)
 	for _, requestedPath := range appSetGenerator.Git.Files {
-		paths, err := g.repos.GetPaths(context.TODO(), appSetGenerator.Git.RepoURL, appSetGenerator.Git.Revision, requestedPath.Path)
+		// The pathspec of the repo server matches more paths than the pattern, they are filtered here
+		paths, err := g.repos.GetPaths(context.TODO(), appSetGenerator.Git.RepoURL, revision, utils.GitPathspec(requestedPath.Path))
 		if err != nil {
 			return nil, err
 		}
 		for _, path := range paths {
-			allPathsMap[path] = true
+			match, err := utils.GlobMatch(requestedPath.Path, path)
+			if err != nil {
+				return nil, err
+			}
+			if match {
+				allPathsMap[path] = true
+			}
 		}
 	}
 
@@ -103,72 +139,183 @@ func (g *GitGenerator) generateParamsForGitFiles(appSetGenerator *argoprojiov1al
 	sort.Strings(allPaths)
 
 	// Generate params from each path, and return
-	res := []map[string]string{}
+	res := []map[string]interface{}{}
 	for _, path := range allPaths {
-		params, err := g.generateParamsFromGitFile(appSetGenerator, path)
+		params, err := g.generateParamsFromGitFile(appSetGenerator, revision, path, useGoTemplate)
 		if err != nil {
 			return nil, err
 		}
 
-		res = append(res, params)
+		res = append(res, params...)
 	}
 	return res, nil
 }
 
-func (g *GitGenerator) generateParamsFromGitFile(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, path string) (map[string]string, error) {
-	content, err := g.repos.GetFileContent(context.TODO(), appSetGenerator.Git.RepoURL, appSetGenerator.Git.Revision, path)
+// generateParamsFromGitFile generates the parameter sets from the content of a JSON or YAML file, see parseGitFile,
+// and from its path, see getPathParams, with path.filename its name. The Go template rendering uses the typed and
+// nested content of the file as is, while the default rendering uses it flattened to string parameters (e.g.
+// "cluster.address"). The content of the file wins over the parameters of its path.
+func (g *GitGenerator) generateParamsFromGitFile(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, revision string, filePath string, useGoTemplate bool) ([]map[string]interface{}, error) {
+	content, err := g.repos.GetFileContent(context.TODO(), appSetGenerator.Git.RepoURL, revision, filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	config := make(map[string]interface{})
-	err = json.Unmarshal(content, &config)
+	configs, err := parseGitFile(filePath, content)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to parse file %s of repository %s at revision %s: %v", filePath, appSetGenerator.Git.RepoURL, revision, err)
 	}
 
-	flat, err := flatten.Flatten(config, "", flatten.DotStyle)
-	if err != nil {
-		return nil, err
+	pathParams := getPathParams(path.Dir(filePath))
+	pathParams["path.filename"] = path.Base(filePath)
+
+	res := make([]map[string]interface{}, len(configs))
+	for i, config := range configs {
+		params := config
+		if !useGoTemplate {
+			flat, err := utils.FlattenParameters(config)
+			if err != nil {
+				return nil, err
+			}
+			params = make(map[string]interface{}, len(flat)+len(pathParams))
+			for k, v := range flat {
+				params[k] = v
+			}
+		}
+
+		for k, v := range pathParams {
+			if _, found := params[k]; !found {
+				params[k] = v
+			}
+		}
+		res[i] = params
 	}
-	params := make(map[string]string)
-	for k, v := range flat {
-		params[k] = v.(string)
+
+	return res, nil
+}
+
+// parseGitFile returns the objects of a JSON or YAML file: its top level object, or the objects of its top level
+// array, for each of its YAML documents. Files with the .json extension are parsed as JSON, and the others as YAML,
+// which JSON content also is.
+func parseGitFile(filePath string, content []byte) ([]map[string]interface{}, error) {
+	var documents [][]byte
+	if strings.ToLower(path.Ext(filePath)) == ".json" {
+		documents = [][]byte{content}
+	} else {
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+		for {
+			document, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			jsonDocument, err := yaml.YAMLToJSON(document)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, jsonDocument)
+		}
 	}
 
-	return params, nil
+	res := []map[string]interface{}{}
+	for _, document := range documents {
+		var value interface{}
+		if err := json.Unmarshal(document, &value); err != nil {
+			return nil, err
+		}
+
+		switch value := value.(type) {
+		case nil:
+			// Empty YAML document
+		case map[string]interface{}:
+			res = append(res, value)
+		case []interface{}:
+			for i, item := range value {
+				object, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("item %d of the top level array is not an object", i)
+				}
+				res = append(res, object)
+			}
+		default:
+			return nil, fmt.Errorf("the top level value is neither an object nor an array")
+		}
+	}
+	return res, nil
 }
 
+// filterApps returns the directories matching an included item and no excluded item, sorted and deduplicated.
 func (g *GitGenerator) filterApps(Directories []argoprojiov1alpha1.GitDirectoryGeneratorItem, allApps []string) []string {
-	res := []string{}
-	for _, requestedPath := range Directories {
-		for _, appPath := range allApps {
-			match, err := path.Match(requestedPath.Path, appPath)
+	matches := map[string]bool{}
+	for _, appPath := range allApps {
+		included, excluded := false, false
+		for _, requestedPath := range Directories {
+			match, err := utils.GlobMatch(requestedPath.Path, appPath)
 			if err != nil {
 				log.WithError(err).WithField("requestedPath", requestedPath).
 					WithField("appPath", appPath).Error("error while matching appPath to requestedPath")
 				continue
 			}
-			if match {
-				res = append(res, appPath)
+			if !match {
+				continue
+			}
+			if requestedPath.Exclude {
+				excluded = true
+			} else {
+				included = true
 			}
 		}
+		if included && !excluded {
+			matches[appPath] = true
+		}
+	}
+
+	res := make([]string, 0, len(matches))
+	for appPath := range matches {
+		res = append(res, appPath)
 	}
+	sort.Strings(res)
 	return res
 }
 
-func (g *GitGenerator) generateParamsFromApps(requestedApps []string, _ *argoprojiov1alpha1.ApplicationSetGenerator) []map[string]string {
+func (g *GitGenerator) generateParamsFromApps(requestedApps []string, _ *argoprojiov1alpha1.ApplicationSetGenerator) []map[string]interface{} {
 	// TODO: At some point, the appicationSetGenerator param should be used
 
-	res := make([]map[string]string, len(requestedApps))
+	res := make([]map[string]interface{}, len(requestedApps))
 	for i, a := range requestedApps {
+		res[i] = getPathParams(a)
+	}
 
-		params := make(map[string]string, 2)
-		params["path"] = a
-		params["path.basename"] = path.Base(a)
+	return res
+}
 
-		res[i] = params
+var invalidNameCharacters = regexp.MustCompile(`[^-a-z0-9.]+`)
+
+// getPathParams returns the parameters of the path of a directory: path, its last segment as path.basename, also
+// normalized to a valid Application name as path.basenameNormalized, and its segments as path[0], path[1], etc.
+func getPathParams(dir string) map[string]interface{} {
+	basename := path.Base(dir)
+	params := map[string]interface{}{
+		"path":                    dir,
+		"path.basename":           basename,
+		"path.basenameNormalized": normalizeName(basename),
+	}
+	if dir != "." {
+		for i, segment := range strings.Split(dir, "/") {
+			params[fmt.Sprintf("path[%d]", i)] = segment
+		}
 	}
+	return params
+}
 
-	return res
+// normalizeName turns a name into a valid Kubernetes resource name: lowercase alphanumerics, dashes and dots, at
+// most 253 characters long.
+func normalizeName(name string) string {
+	name = invalidNameCharacters.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-.")
 }
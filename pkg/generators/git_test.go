@@ -23,12 +23,24 @@ type argoCDServiceMock struct {
 	mock *mock.Mock
 }
 
+func (a argoCDServiceMock) ResolveRevision(ctx context.Context, repoURL string, revision string) (string, error) {
+	args := a.mock.Called(ctx, repoURL, revision)
+
+	return args.String(0), args.Error(1)
+}
+
 func (a argoCDServiceMock) GetApps(ctx context.Context, repoURL string, revision string) ([]string, error) {
 	args := a.mock.Called(ctx, repoURL, revision)
 
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (a argoCDServiceMock) GetDirectories(ctx context.Context, repoURL string, revision string) ([]string, error) {
+	args := a.mock.Called(ctx, repoURL, revision)
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (a argoCDServiceMock) GetPaths(ctx context.Context, repoURL string, revision string, pattern string) ([]string, error) {
 	args := a.mock.Called(ctx, repoURL, revision, pattern)
 
@@ -41,15 +53,19 @@ func (a argoCDServiceMock) GetFileContent(ctx context.Context, repoURL string, r
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+// commitSHA is the commit resolved from the revision of the Git generators
+const commitSHA = "d9a6e8ad11fcc6a6e0ce8b2c6d4c4e3d6b0eb8a5"
+
 func TestGitGenerateParamsFromDirectories(t *testing.T) {
 
 	cases := []struct {
-		name          string
-		directories   []argoprojiov1alpha1.GitDirectoryGeneratorItem
-		repoApps      []string
-		repoError     error
-		expected      []map[string]string
-		expectedError error
+		name               string
+		directories        []argoprojiov1alpha1.GitDirectoryGeneratorItem
+		directoryDiscovery string
+		repoApps           []string
+		repoError          error
+		expected           []map[string]interface{}
+		expectedError      error
 	}{
 		{
 			name:        "happy flow - created apps",
@@ -60,9 +76,9 @@ func TestGitGenerateParamsFromDirectories(t *testing.T) {
 				"p1/app3",
 			},
 			repoError: nil,
-			expected: []map[string]string{
-				{"path": "app1", "path.basename": "app1"},
-				{"path": "app2", "path.basename": "app2"},
+			expected: []map[string]interface{}{
+				{"path": "app1", "path.basename": "app1", "path.basenameNormalized": "app1", "path[0]": "app1"},
+				{"path": "app2", "path.basename": "app2", "path.basenameNormalized": "app2", "path[0]": "app2"},
 			},
 			expectedError: nil,
 		},
@@ -76,18 +92,76 @@ func TestGitGenerateParamsFromDirectories(t *testing.T) {
 				"p1/p2/p3/app4",
 			},
 			repoError: nil,
-			expected: []map[string]string{
-				{"path": "p1/app2", "path.basename": "app2"},
-				{"path": "p1/p2/app3", "path.basename": "app3"},
+			expected: []map[string]interface{}{
+				{"path": "p1/app2", "path.basename": "app2", "path.basenameNormalized": "app2", "path[0]": "p1", "path[1]": "app2"},
+				{"path": "p1/p2/app3", "path.basename": "app3", "path.basenameNormalized": "app3", "path[0]": "p1", "path[1]": "p2", "path[2]": "app3"},
 			},
 			expectedError: nil,
 		},
+		{
+			name: "excluded directories win and recursive globs match any depth",
+			directories: []argoprojiov1alpha1.GitDirectoryGeneratorItem{
+				{Path: "apps/**"},
+				{Path: "apps/*"},
+				{Path: "apps/experimental", Exclude: true},
+				{Path: "apps/**/legacy-*", Exclude: true},
+			},
+			repoApps: []string{
+				"apps/prod/guestbook",
+				"apps/experimental",
+				"apps/guestbook",
+				"apps/legacy-guestbook",
+				"apps/prod/legacy-guestbook",
+				"other/guestbook",
+			},
+			repoError: nil,
+			expected: []map[string]interface{}{
+				{"path": "apps/guestbook", "path.basename": "guestbook", "path.basenameNormalized": "guestbook", "path[0]": "apps", "path[1]": "guestbook"},
+				{"path": "apps/prod/guestbook", "path.basename": "guestbook", "path.basenameNormalized": "guestbook", "path[0]": "apps", "path[1]": "prod", "path[2]": "guestbook"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:               "all directories",
+			directories:        []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "apps/*"}},
+			directoryDiscovery: argoprojiov1alpha1.GitDirectoryDiscoveryAll,
+			repoApps:           []string{"apps", "apps/guestbook", "apps/plain-yaml", "apps/plain-yaml/base"},
+			repoError:          nil,
+			expected: []map[string]interface{}{
+				{"path": "apps/guestbook", "path.basename": "guestbook", "path.basenameNormalized": "guestbook", "path[0]": "apps", "path[1]": "guestbook"},
+				{"path": "apps/plain-yaml", "path.basename": "plain-yaml", "path.basenameNormalized": "plain-yaml", "path[0]": "apps", "path[1]": "plain-yaml"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:               "handles error listing all directories",
+			directories:        []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+			directoryDiscovery: argoprojiov1alpha1.GitDirectoryDiscoveryAll,
+			repoApps:           []string{},
+			repoError:          fmt.Errorf("error"),
+			expected:           []map[string]interface{}{},
+			expectedError:      fmt.Errorf("error"),
+		},
+		{
+			name:               "unknown directory discovery",
+			directories:        []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+			directoryDiscovery: "Some",
+			expectedError:      fmt.Errorf(`unknown directory discovery "Some", expected Apps or All`),
+		},
+		{
+			name:          "exclusions only",
+			directories:   []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "apps/experimental", Exclude: true}},
+			repoApps:      []string{"apps/experimental", "apps/guestbook"},
+			repoError:     nil,
+			expected:      []map[string]interface{}{},
+			expectedError: nil,
+		},
 		{
 			name:          "handles empty response from repo server",
 			directories:   []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
 			repoApps:      []string{},
 			repoError:     nil,
-			expected:      []map[string]string{},
+			expected:      []map[string]interface{}{},
 			expectedError: nil,
 		},
 		{
@@ -95,7 +169,7 @@ func TestGitGenerateParamsFromDirectories(t *testing.T) {
 			directories:   []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
 			repoApps:      []string{},
 			repoError:     fmt.Errorf("error"),
-			expected:      []map[string]string{},
+			expected:      []map[string]interface{}{},
 			expectedError: fmt.Errorf("error"),
 		},
 	}
@@ -104,7 +178,13 @@ func TestGitGenerateParamsFromDirectories(t *testing.T) {
 		cc := c
 		t.Run(cc.name, func(t *testing.T) {
 			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
-			argoCDServiceMock.mock.On("GetApps", mock.Anything, mock.Anything, mock.Anything).Return(c.repoApps, c.repoError)
+			argoCDServiceMock.mock.On("ResolveRevision", mock.Anything, "RepoURL", "Revision").Return(commitSHA, nil)
+			switch c.directoryDiscovery {
+			case "", argoprojiov1alpha1.GitDirectoryDiscoveryApps:
+				argoCDServiceMock.mock.On("GetApps", mock.Anything, "RepoURL", commitSHA).Return(c.repoApps, c.repoError)
+			case argoprojiov1alpha1.GitDirectoryDiscoveryAll:
+				argoCDServiceMock.mock.On("GetDirectories", mock.Anything, "RepoURL", commitSHA).Return(c.repoApps, c.repoError)
+			}
 
 			var gitGenerator = NewGitGenerator(argoCDServiceMock)
 			applicationSetInfo := argoprojiov1alpha1.ApplicationSet{
@@ -114,15 +194,16 @@ func TestGitGenerateParamsFromDirectories(t *testing.T) {
 				Spec: argoprojiov1alpha1.ApplicationSetSpec{
 					Generators: []argoprojiov1alpha1.ApplicationSetGenerator{{
 						Git: &argoprojiov1alpha1.GitGenerator{
-							RepoURL:     "RepoURL",
-							Revision:    "Revision",
-							Directories: c.directories,
+							RepoURL:            "RepoURL",
+							Revision:           "Revision",
+							Directories:        c.directories,
+							DirectoryDiscovery: c.directoryDiscovery,
 						},
 					}},
 				},
 			}
 
-			got, err := gitGenerator.GenerateParams(&applicationSetInfo.Spec.Generators[0])
+			got, err := gitGenerator.GenerateParams(&applicationSetInfo.Spec.Generators[0], &applicationSetInfo)
 
 			if c.expectedError != nil {
 				assert.EqualError(t, err, c.expectedError.Error())
@@ -146,7 +227,8 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 		repoFileContents       map[string][]byte
 		repoPathsError         error
 		repoFileContentsErrors map[string]error
-		expected               []map[string]string
+		useGoTemplate          bool
+		expected               []map[string]interface{}
 		expectedError          error
 	}{
 		{
@@ -181,23 +263,329 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 			},
 			repoPathsError:         nil,
 			repoFileContentsErrors: nil,
-			expected: []map[string]string{
+			expected: []map[string]interface{}{
 				{
-					"cluster.owner":        "john.doe@example.com",
-					"cluster.name":         "production",
-					"cluster.address":      "https://kubernetes.default.svc",
-					"key1":                 "val1",
-					"key2.key2_1":          "val2_1",
-					"key2.key2_2.key2_2_1": "val2_2_1",
+					"cluster.owner":           "john.doe@example.com",
+					"cluster.name":            "production",
+					"cluster.address":         "https://kubernetes.default.svc",
+					"key1":                    "val1",
+					"key2.key2_1":             "val2_1",
+					"key2.key2_2.key2_2_1":    "val2_2_1",
+					"path":                    "cluster-config/production",
+					"path.basename":           "production",
+					"path.basenameNormalized": "production",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "production",
+					"path.filename":           "config.json",
 				},
 				{
-					"cluster.owner":   "foo.bar@example.com",
-					"cluster.name":    "staging",
-					"cluster.address": "https://kubernetes.default.svc",
+					"cluster.owner":           "foo.bar@example.com",
+					"cluster.name":            "staging",
+					"cluster.address":         "https://kubernetes.default.svc",
+					"path":                    "cluster-config/staging",
+					"path.basename":           "staging",
+					"path.basenameNormalized": "staging",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "staging",
+					"path.filename":           "config.json",
 				},
 			},
 			expectedError: nil,
 		},
+		{
+			name:      "non-string values are flattened to strings",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.json"}},
+			repoPaths: []string{"cluster-config/production/config.json"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/production/config.json": []byte(`{
+   "cluster": {
+       "name": "production",
+       "replicas": 3,
+       "enabled": true,
+       "regions": ["eu", "us"]
+   },
+   "owner": null
+}`),
+			},
+			expected: []map[string]interface{}{
+				{
+					"cluster.name":            "production",
+					"cluster.replicas":        "3",
+					"cluster.enabled":         "true",
+					"cluster.regions.0":       "eu",
+					"cluster.regions.1":       "us",
+					"owner":                   "",
+					"path":                    "cluster-config/production",
+					"path.basename":           "production",
+					"path.basenameNormalized": "production",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "production",
+					"path.filename":           "config.json",
+				},
+			},
+		},
+		{
+			name:      "typed and nested values are kept for go templates",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.json"}},
+			repoPaths: []string{"cluster-config/production/config.json"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/production/config.json": []byte(`{
+   "cluster": {
+       "name": "production",
+       "replicas": 3,
+       "enabled": true,
+       "regions": ["eu", "us"]
+   }
+}`),
+			},
+			useGoTemplate: true,
+			expected: []map[string]interface{}{
+				{
+					"cluster": map[string]interface{}{
+						"name":     "production",
+						"replicas": float64(3),
+						"enabled":  true,
+						"regions":  []interface{}{"eu", "us"},
+					},
+					"path":                    "cluster-config/production",
+					"path.basename":           "production",
+					"path.basenameNormalized": "production",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "production",
+					"path.filename":           "config.json",
+				},
+			},
+		},
+		{
+			name:      "yaml files, with multiple documents",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.yaml"}},
+			repoPaths: []string{"cluster-config/production/config.yaml", "cluster-config/staging/config.yaml"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/production/config.yaml": []byte(`cluster:
+  name: production
+  replicas: 3
+---
+cluster:
+  name: production-eu
+  replicas: 2
+---
+`),
+				"cluster-config/staging/config.yaml": []byte(`# Staging
+cluster:
+  name: staging
+  replicas: 1
+`),
+			},
+			expected: []map[string]interface{}{
+				{
+					"cluster.name":            "production",
+					"cluster.replicas":        "3",
+					"path":                    "cluster-config/production",
+					"path.basename":           "production",
+					"path.basenameNormalized": "production",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "production",
+					"path.filename":           "config.yaml",
+				},
+				{
+					"cluster.name":            "production-eu",
+					"cluster.replicas":        "2",
+					"path":                    "cluster-config/production",
+					"path.basename":           "production",
+					"path.basenameNormalized": "production",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "production",
+					"path.filename":           "config.yaml",
+				},
+				{
+					"cluster.name":            "staging",
+					"cluster.replicas":        "1",
+					"path":                    "cluster-config/staging",
+					"path.basename":           "staging",
+					"path.basenameNormalized": "staging",
+					"path[0]":                 "cluster-config",
+					"path[1]":                 "staging",
+					"path.filename":           "config.yaml",
+				},
+			},
+		},
+		{
+			name:      "top level arrays",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/clusters.*"}},
+			repoPaths: []string{"cluster-config/clusters.json", "cluster-config/clusters.yml"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/clusters.json": []byte(`[{"name": "production"}, {"name": "staging"}]`),
+				"cluster-config/clusters.yml": []byte(`- name: dev
+- name: test
+`),
+			},
+			useGoTemplate: true,
+			expected: []map[string]interface{}{
+				{
+					"name":                    "production",
+					"path":                    "cluster-config",
+					"path.basename":           "cluster-config",
+					"path.basenameNormalized": "cluster-config",
+					"path[0]":                 "cluster-config",
+					"path.filename":           "clusters.json",
+				},
+				{
+					"name":                    "staging",
+					"path":                    "cluster-config",
+					"path.basename":           "cluster-config",
+					"path.basenameNormalized": "cluster-config",
+					"path[0]":                 "cluster-config",
+					"path.filename":           "clusters.json",
+				},
+				{
+					"name":                    "dev",
+					"path":                    "cluster-config",
+					"path.basename":           "cluster-config",
+					"path.basenameNormalized": "cluster-config",
+					"path[0]":                 "cluster-config",
+					"path.filename":           "clusters.yml",
+				},
+				{
+					"name":                    "test",
+					"path":                    "cluster-config",
+					"path.basename":           "cluster-config",
+					"path.basenameNormalized": "cluster-config",
+					"path[0]":                 "cluster-config",
+					"path.filename":           "clusters.yml",
+				},
+			},
+		},
+		{
+			name:      "json content with another extension",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config"}},
+			repoPaths: []string{"cluster-config/config"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/config": []byte(`{"cluster": {"name": "production"}}`),
+			},
+			expected: []map[string]interface{}{
+				{
+					"cluster.name":            "production",
+					"path":                    "cluster-config",
+					"path.basename":           "cluster-config",
+					"path.basenameNormalized": "cluster-config",
+					"path[0]":                 "cluster-config",
+					"path.filename":           "config",
+				},
+			},
+		},
+		{
+			name:      "path parameters",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.json"}},
+			repoPaths: []string{"clusters/prod/eu-west/config.json", "clusters/Staging_EU/config.json", "config.json"},
+			repoFileContents: map[string][]byte{
+				"clusters/prod/eu-west/config.json": []byte(`{"name": "prod-eu-west"}`),
+				"clusters/Staging_EU/config.json":   []byte(`{"name": "staging-eu", "path": "apps/staging"}`),
+				"config.json":                       []byte(`{"name": "default"}`),
+			},
+			expected: []map[string]interface{}{
+				{
+					"name":                    "prod-eu-west",
+					"path":                    "clusters/prod/eu-west",
+					"path.basename":           "eu-west",
+					"path.basenameNormalized": "eu-west",
+					"path[0]":                 "clusters",
+					"path[1]":                 "prod",
+					"path[2]":                 "eu-west",
+					"path.filename":           "config.json",
+				},
+				{
+					"name":                    "staging-eu",
+					"path":                    "apps/staging",
+					"path.basename":           "Staging_EU",
+					"path.basenameNormalized": "staging-eu",
+					"path[0]":                 "clusters",
+					"path[1]":                 "Staging_EU",
+					"path.filename":           "config.json",
+				},
+				{
+					"name":                    "default",
+					"path":                    ".",
+					"path.basename":           ".",
+					"path.basenameNormalized": "",
+					"path.filename":           "config.json",
+				},
+			},
+		},
+		{
+			name:      "recursive globs",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "clusters/**/config.json"}, {Path: "clusters/*/config.json"}},
+			repoPaths: []string{"clusters/config.json", "clusters/prod/config.json", "clusters/prod/eu-west/config.json", "clusters/prod/eu-west/myconfig.json"},
+			repoFileContents: map[string][]byte{
+				"clusters/config.json":              []byte(`{"name": "default"}`),
+				"clusters/prod/config.json":         []byte(`{"name": "prod"}`),
+				"clusters/prod/eu-west/config.json": []byte(`{"name": "prod-eu-west"}`),
+			},
+			expected: []map[string]interface{}{
+				{
+					"name":                    "default",
+					"path":                    "clusters",
+					"path.basename":           "clusters",
+					"path.basenameNormalized": "clusters",
+					"path[0]":                 "clusters",
+					"path.filename":           "config.json",
+				},
+				{
+					"name":                    "prod",
+					"path":                    "clusters/prod",
+					"path.basename":           "prod",
+					"path.basenameNormalized": "prod",
+					"path[0]":                 "clusters",
+					"path[1]":                 "prod",
+					"path.filename":           "config.json",
+				},
+				{
+					"name":                    "prod-eu-west",
+					"path":                    "clusters/prod/eu-west",
+					"path.basename":           "eu-west",
+					"path.basenameNormalized": "eu-west",
+					"path[0]":                 "clusters",
+					"path[1]":                 "prod",
+					"path[2]":                 "eu-west",
+					"path.filename":           "config.json",
+				},
+			},
+		},
+		{
+			name:      "invalid yaml",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.yaml"}},
+			repoPaths: []string{"cluster-config/config.yaml"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/config.yaml": []byte("cluster: [production"),
+			},
+			expectedError: fmt.Errorf("unable to parse file cluster-config/config.yaml of repository RepoURL at revision %s: yaml: line 1: did not find expected ',' or ']'", commitSHA),
+		},
+		{
+			name:      "invalid json",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.json"}},
+			repoPaths: []string{"cluster-config/config.json"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/config.json": []byte("cluster: production"),
+			},
+			expectedError: fmt.Errorf("unable to parse file cluster-config/config.json of repository RepoURL at revision %s: invalid character 'c' looking for beginning of value", commitSHA),
+		},
+		{
+			name:      "array of scalars",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.yaml"}},
+			repoPaths: []string{"cluster-config/config.yaml"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/config.yaml": []byte("- name: production\n- staging\n"),
+			},
+			expectedError: fmt.Errorf("unable to parse file cluster-config/config.yaml of repository RepoURL at revision %s: item 1 of the top level array is not an object", commitSHA),
+		},
+		{
+			name:      "scalar",
+			files:     []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.yaml"}},
+			repoPaths: []string{"cluster-config/config.yaml"},
+			repoFileContents: map[string][]byte{
+				"cluster-config/config.yaml": []byte("production"),
+			},
+			expectedError: fmt.Errorf("unable to parse file cluster-config/config.yaml of repository RepoURL at revision %s: the top level value is neither an object nor an array", commitSHA),
+		},
 		{
 			name:                   "handles error during getting repo paths",
 			files:                  []argoprojiov1alpha1.GitFileGeneratorItem{{Path: "**/config.json"}},
@@ -205,7 +593,7 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 			repoFileContents:       map[string][]byte{},
 			repoPathsError:         fmt.Errorf("paths error"),
 			repoFileContentsErrors: nil,
-			expected:               []map[string]string{},
+			expected:               []map[string]interface{}{},
 			expectedError:          fmt.Errorf("paths error"),
 		},
 		{
@@ -230,7 +618,7 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 				"cluster-config/production/config.json": nil,
 				"cluster-config/staging/config.json":    fmt.Errorf("staging config file get content error"),
 			},
-			expected:      []map[string]string{},
+			expected:      []map[string]interface{}{},
 			expectedError: fmt.Errorf("staging config file get content error"),
 		},
 	}
@@ -239,12 +627,17 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 		cc := c
 		t.Run(cc.name, func(t *testing.T) {
 			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
-			argoCDServiceMock.mock.On("GetPaths", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(c.repoPaths, c.repoPathsError)
+			argoCDServiceMock.mock.On("ResolveRevision", mock.Anything, "RepoURL", "Revision").Return(commitSHA, nil)
+			argoCDServiceMock.mock.On("GetPaths", mock.Anything, "RepoURL", commitSHA, mock.Anything).Return(c.repoPaths, c.repoPathsError)
 
 			if c.repoPaths != nil {
 				for _, repoPath := range c.repoPaths {
 					fmt.Println("repoPath: ", repoPath)
-					argoCDServiceMock.mock.On("GetFileContent", mock.Anything, mock.Anything, mock.Anything, repoPath).Return(c.repoFileContents[repoPath], c.repoFileContentsErrors[repoPath]).Once()
+					// The paths without content are filtered out by the generator
+					if _, found := c.repoFileContents[repoPath]; !found {
+						continue
+					}
+					argoCDServiceMock.mock.On("GetFileContent", mock.Anything, "RepoURL", commitSHA, repoPath).Return(c.repoFileContents[repoPath], c.repoFileContentsErrors[repoPath]).Once()
 				}
 			}
 
@@ -254,6 +647,7 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 					Name: "set",
 				},
 				Spec: argoprojiov1alpha1.ApplicationSetSpec{
+					GoTemplate: c.useGoTemplate,
 					Generators: []argoprojiov1alpha1.ApplicationSetGenerator{{
 						Git: &argoprojiov1alpha1.GitGenerator{
 							RepoURL:  "RepoURL",
@@ -264,7 +658,7 @@ func TestGitGenerateParamsFromFiles(t *testing.T) {
 				},
 			}
 
-			got, err := gitGenerator.GenerateParams(&applicationSetInfo.Spec.Generators[0])
+			got, err := gitGenerator.GenerateParams(&applicationSetInfo.Spec.Generators[0], &applicationSetInfo)
 			fmt.Println(got, err)
 
 			if c.expectedError != nil {
@@ -12,7 +12,11 @@ type Generator interface {
 	// GenerateParams interprets the ApplicationSet and generates all relevant parameters for the application template.
 	// The expected / desired list of parameters is returned, it then will be render and reconciled
 	// against the current state of the Applications in the cluster.
-	GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) ([]map[string]string, error)
+	// The ApplicationSet owning the generator is passed along, for generators that need its metadata (e.g. its
+	// namespace, to look up referenced Secrets).
+	// Parameter values may be of any JSON type, including nested maps and lists: generators reading structured
+	// data only keep them as is when the ApplicationSet uses Go templates, and flatten them to strings otherwise.
+	GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error)
 
 	// GetRequeueAfter is the the generator can controller the next reconciled loop
 	// In case there is more then one generator the time will be the minimum of the times.
@@ -21,6 +25,9 @@ type Generator interface {
 
 	// GetTemplate returns the inline template from the spec if there is any, or an empty object otherwise
 	GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate
+
+	// GetFilters returns the filters restricting the generated parameter sets, see FilterParams
+	GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter
 }
 
 var EmptyAppSetGeneratorError = errors.New("ApplicationSet is empty")
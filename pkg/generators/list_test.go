@@ -11,15 +11,15 @@ import (
 func TestGenerateListParams(t *testing.T) {
 	testCases := []struct {
 		elements []argoprojiov1alpha1.ListGeneratorElement
-		expected []map[string]string
+		expected []map[string]interface{}
 	}{
 		{
-			elements: []argoprojiov1alpha1.ListGeneratorElement{{Cluster: "cluster", Url: "url", Values: map[string]string{}}}, expected: []map[string]string{{
+			elements: []argoprojiov1alpha1.ListGeneratorElement{{Cluster: "cluster", Url: "url", Values: map[string]string{}}}, expected: []map[string]interface{}{{
 				"cluster": "cluster", "url": "url"},
 			},
 		},
 		{
-			elements: []argoprojiov1alpha1.ListGeneratorElement{{Cluster: "cluster", Url: "url", Values: map[string]string{"foo": "bar"}}}, expected: []map[string]string{{
+			elements: []argoprojiov1alpha1.ListGeneratorElement{{Cluster: "cluster", Url: "url", Values: map[string]string{"foo": "bar"}}}, expected: []map[string]interface{}{{
 				"cluster": "cluster", "url": "url", "values.foo": "bar",
 			}},
 		},
@@ -31,7 +31,7 @@ func TestGenerateListParams(t *testing.T) {
 
 		got, err := listGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{List: &argoprojiov1alpha1.ListGenerator{
 			Elements: testCase.elements,
-		}})
+		}}, &argoprojiov1alpha1.ApplicationSet{})
 
 		assert.NoError(t, err)
 		assert.ElementsMatch(t, testCase.expected, got)
@@ -0,0 +1,84 @@
+package generators
+
+import (
+	"errors"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+var _ Generator = (*MatrixGenerator)(nil)
+
+var (
+	MoreThanTwoGeneratorsInMatrixError = errors.New("found more than two generators, Matrix supports only two")
+	LessThanTwoGeneratorsInMatrixError = errors.New("found less than two generators, Matrix supports only two")
+)
+
+// MatrixGenerator combines the parameters of two child generators, by producing their cartesian product.
+type MatrixGenerator struct {
+	// The inner generators supported by the matrix generator (cluster, git, list...)
+	supportedGenerators map[string]Generator
+}
+
+func NewMatrixGenerator(supportedGenerators map[string]Generator) Generator {
+	m := &MatrixGenerator{
+		supportedGenerators: supportedGenerators,
+	}
+	return m
+}
+
+func (m *MatrixGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.Matrix == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if len(appSetGenerator.Matrix.Generators) < 2 {
+		return nil, LessThanTwoGeneratorsInMatrixError
+	}
+
+	if len(appSetGenerator.Matrix.Generators) > 2 {
+		return nil, MoreThanTwoGeneratorsInMatrixError
+	}
+
+	res := []map[string]interface{}{}
+
+	g0, err := generateNestedParams(appSetGenerator.Matrix.Generators[0], m.supportedGenerators, applicationSetInfo)
+	if err != nil {
+		return nil, err
+	}
+	g1, err := generateNestedParams(appSetGenerator.Matrix.Generators[1], m.supportedGenerators, applicationSetInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range g0 {
+		for _, b := range g1 {
+			val, err := utils.CombineMaps(a, b)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, val)
+		}
+	}
+
+	return res, nil
+}
+
+// GetRequeueAfter returns the smallest requeue duration requested by any of the child generators.
+func (m *MatrixGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	return getNestedRequeueAfter(appSetGenerator.Matrix.Generators, m.supportedGenerators)
+}
+
+func (m *MatrixGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.Matrix.Template
+}
+
+func (m *MatrixGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.Matrix.Filters
+}
@@ -0,0 +1,323 @@
+package generators
+
+import (
+	"testing"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatrixGenerate(t *testing.T) {
+
+	gitGenerator := &argoprojiov1alpha1.GitGenerator{
+		RepoURL:     "RepoURL",
+		Revision:    "Revision",
+		Directories: []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+	}
+
+	listGenerator := &argoprojiov1alpha1.ListGenerator{
+		Elements: []argoprojiov1alpha1.ListGeneratorElement{
+			{Cluster: "Cluster", Url: "Url"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		baseGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		expectedErr    error
+		expected       []map[string]interface{}
+	}{
+		{
+			name: "happy flow - generate params",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					Git: gitGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+			},
+			expected: []map[string]interface{}{
+				{"path": "app1", "path.basename": "app1", "path.basenameNormalized": "app1", "path[0]": "app1", "cluster": "Cluster", "url": "Url"},
+				{"path": "app2", "path.basename": "app2", "path.basenameNormalized": "app2", "path[0]": "app2", "cluster": "Cluster", "url": "Url"},
+			},
+		},
+		{
+			name: "returns error if there is less than two base generators",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					Git: gitGenerator,
+				},
+			},
+			expectedErr: LessThanTwoGeneratorsInMatrixError,
+		},
+		{
+			name: "returns error if there is more than two base generators",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					List: listGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+			},
+			expectedErr: MoreThanTwoGeneratorsInMatrixError,
+		},
+		{
+			name: "returns error if there is more than one inner generator in the same base generator",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					Git:  gitGenerator,
+					List: listGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+			},
+			expectedErr: MoreThanOneInnerGeneratorError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase // Since tests may run in parallel
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
+			argoCDServiceMock.mock.On("ResolveRevision", mock.Anything, mock.Anything, mock.Anything).Return(commitSHA, nil)
+			argoCDServiceMock.mock.On("GetApps", mock.Anything, mock.Anything, commitSHA).Return([]string{"app1", "app2"}, nil)
+
+			var matrixGenerator = NewMatrixGenerator(
+				map[string]Generator{
+					"Git":  NewGitGenerator(argoCDServiceMock),
+					"List": NewListGenerator(),
+				},
+			)
+
+			got, err := matrixGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Matrix: &argoprojiov1alpha1.MatrixGenerator{
+					Generators: testCaseCopy.baseGenerators,
+					Template:   argoprojiov1alpha1.ApplicationSetTemplate{},
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{})
+
+			if testCaseCopy.expectedErr != nil {
+				assert.EqualError(t, err, testCaseCopy.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatrixGenerateKeyCollision(t *testing.T) {
+
+	listGenerator := func(cluster string) *argoprojiov1alpha1.ListGenerator {
+		return &argoprojiov1alpha1.ListGenerator{
+			Elements: []argoprojiov1alpha1.ListGeneratorElement{
+				{Cluster: cluster, Url: "Url"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		baseGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		expectedErr    bool
+		expected       []map[string]interface{}
+	}{
+		{
+			name: "identical values for the same key are combined",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator("Cluster")},
+				{List: listGenerator("Cluster")},
+			},
+			expected: []map[string]interface{}{
+				{"cluster": "Cluster", "url": "Url"},
+			},
+		},
+		{
+			name: "different values for the same key return an error",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator("Cluster1")},
+				{List: listGenerator("Cluster2")},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			var matrixGenerator = NewMatrixGenerator(
+				map[string]Generator{
+					"List": NewListGenerator(),
+				},
+			)
+
+			got, err := matrixGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Matrix: &argoprojiov1alpha1.MatrixGenerator{
+					Generators: testCaseCopy.baseGenerators,
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{})
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatrixGenerateFilters(t *testing.T) {
+
+	listGenerator := func(filters []argoprojiov1alpha1.GeneratorFilter, clusters ...string) *argoprojiov1alpha1.ListGenerator {
+		elements := []argoprojiov1alpha1.ListGeneratorElement{}
+		for _, cluster := range clusters {
+			elements = append(elements, argoprojiov1alpha1.ListGeneratorElement{Cluster: cluster, Url: "Url-" + cluster})
+		}
+		return &argoprojiov1alpha1.ListGenerator{Elements: elements, Filters: filters}
+	}
+
+	gitGenerator := &argoprojiov1alpha1.GitGenerator{
+		RepoURL:     "RepoURL",
+		Revision:    "Revision",
+		Directories: []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+		Filters:     []argoprojiov1alpha1.GeneratorFilter{{Expr: `{{path}} != "app2"`}},
+	}
+
+	testCases := []struct {
+		name           string
+		baseGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		filters        []argoprojiov1alpha1.GeneratorFilter
+		expectedErr    bool
+		expected       []map[string]interface{}
+	}{
+		{
+			name: "filters of the child generators are applied",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{Git: gitGenerator},
+				{List: listGenerator([]argoprojiov1alpha1.GeneratorFilter{{Expr: `{{cluster}} matches "^prod-"`}}, "prod-1", "staging-1")},
+			},
+			expected: []map[string]interface{}{
+				{"path": "app1", "path.basename": "app1", "path.basenameNormalized": "app1", "path[0]": "app1", "cluster": "prod-1", "url": "Url-prod-1"},
+			},
+		},
+		{
+			name: "invalid filters of the child generators return an error",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{Git: gitGenerator},
+				{List: listGenerator([]argoprojiov1alpha1.GeneratorFilter{{Expr: `{{cluster}} matches`}}, "prod-1")},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
+			argoCDServiceMock.mock.On("ResolveRevision", mock.Anything, mock.Anything, mock.Anything).Return(commitSHA, nil)
+			argoCDServiceMock.mock.On("GetApps", mock.Anything, mock.Anything, commitSHA).Return([]string{"app1", "app2"}, nil)
+
+			var matrixGenerator = NewMatrixGenerator(
+				map[string]Generator{
+					"Git":  NewGitGenerator(argoCDServiceMock),
+					"List": NewListGenerator(),
+				},
+			)
+
+			got, err := matrixGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Matrix: &argoprojiov1alpha1.MatrixGenerator{
+					Generators: testCaseCopy.baseGenerators,
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{})
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatrixGetRequeueAfter(t *testing.T) {
+
+	gitGenerator := &argoprojiov1alpha1.GitGenerator{
+		RepoURL:             "RepoURL",
+		Revision:            "Revision",
+		Directories:         []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+		RequeueAfterSeconds: 30,
+	}
+
+	listGenerator := &argoprojiov1alpha1.ListGenerator{
+		Elements: []argoprojiov1alpha1.ListGeneratorElement{
+			{Cluster: "Cluster", Url: "Url"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		baseGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		expected       time.Duration
+	}{
+		{
+			name: "return NoRequeueAfter if all the inner baseGenerators returns it",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					List: listGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+			},
+			expected: NoRequeueAfter,
+		},
+		{
+			name: "returns the minimal time",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{
+					Git: gitGenerator,
+				},
+				{
+					List: listGenerator,
+				},
+			},
+			expected: time.Duration(30) * time.Second,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
+
+			var matrixGenerator = NewMatrixGenerator(
+				map[string]Generator{
+					"Git":  NewGitGenerator(argoCDServiceMock),
+					"List": NewListGenerator(),
+				},
+			)
+
+			got := matrixGenerator.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Matrix: &argoprojiov1alpha1.MatrixGenerator{
+					Generators: testCaseCopy.baseGenerators,
+				},
+			})
+
+			assert.Equal(t, testCaseCopy.expected, got)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package generators
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+var _ Generator = (*MergeGenerator)(nil)
+
+var (
+	LessThanTwoGeneratorsInMergeError = errors.New("found less than two generators, Merge requires two or more")
+	NoMergeKeysError                  = errors.New("no merge keys were specified, Merge requires at least one")
+)
+
+// MergeGenerator merges the parameters of two or more child generators, matching them on the merge keys.
+type MergeGenerator struct {
+	// The inner generators supported by the merge generator (cluster, git, list...)
+	supportedGenerators map[string]Generator
+}
+
+func NewMergeGenerator(supportedGenerators map[string]Generator) Generator {
+	m := &MergeGenerator{
+		supportedGenerators: supportedGenerators,
+	}
+	return m
+}
+
+func (m *MergeGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.Merge == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if len(appSetGenerator.Merge.Generators) < 2 {
+		return nil, LessThanTwoGeneratorsInMergeError
+	}
+
+	if len(appSetGenerator.Merge.MergeKeys) == 0 {
+		return nil, NoMergeKeysError
+	}
+
+	mergeKeys := appSetGenerator.Merge.MergeKeys
+
+	baseParams, err := generateNestedParams(appSetGenerator.Merge.Generators[0], m.supportedGenerators, applicationSetInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// The base parameter sets are indexed by their merge key, while keeping their original order, so
+	// that the merged result is deterministic.
+	baseParamsByMergeKey, err := getParamSetsByMergeKey(mergeKeys, baseParams)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, nestedGenerator := range appSetGenerator.Merge.Generators[1:] {
+		params, err := generateNestedParams(nestedGenerator, m.supportedGenerators, applicationSetInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		paramsByMergeKey, err := getParamSetsByMergeKey(mergeKeys, params)
+		if err != nil {
+			return nil, fmt.Errorf("error in generator %d of merge: %w", i+1, err)
+		}
+
+		for mergeKey, override := range paramsByMergeKey {
+			base, found := baseParamsByMergeKey[mergeKey]
+			if !found {
+				continue
+			}
+			for key, value := range override {
+				base[key] = value
+			}
+		}
+	}
+
+	res := make([]map[string]interface{}, 0, len(baseParams))
+	for _, params := range baseParams {
+		res = append(res, params)
+	}
+
+	return res, nil
+}
+
+// getParamSetsByMergeKey indexes the parameter sets by the values of their merge keys. The parameter sets are
+// copied, so that they can be modified by the caller. Two parameter sets with the same merge key values are an
+// error, since it would be ambiguous which one should be merged. The parameter sets missing a merge key are not
+// indexed, so they are never merged: they would otherwise all match each other.
+func getParamSetsByMergeKey(mergeKeys []string, paramSets []map[string]interface{}) (map[string]map[string]interface{}, error) {
+	res := make(map[string]map[string]interface{}, len(paramSets))
+
+	for i, paramSet := range paramSets {
+		mergeKeyValues := make(map[string]interface{}, len(mergeKeys))
+		for _, mergeKey := range mergeKeys {
+			value, found := paramSet[mergeKey]
+			if !found {
+				break
+			}
+			mergeKeyValues[mergeKey] = value
+		}
+		if len(mergeKeyValues) != len(mergeKeys) {
+			continue
+		}
+
+		mergeKeyJSON, err := json.Marshal(mergeKeyValues)
+		if err != nil {
+			return nil, err
+		}
+		key := string(mergeKeyJSON)
+
+		if _, exists := res[key]; exists {
+			return nil, fmt.Errorf("found more than one parameter set with the same merge key values %s, merge keys must be unique", key)
+		}
+
+		copied := make(map[string]interface{}, len(paramSet))
+		for k, v := range paramSet {
+			copied[k] = v
+		}
+		res[key] = copied
+		paramSets[i] = copied
+	}
+
+	return res, nil
+}
+
+// GetRequeueAfter returns the smallest requeue duration requested by any of the child generators.
+func (m *MergeGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	return getNestedRequeueAfter(appSetGenerator.Merge.Generators, m.supportedGenerators)
+}
+
+func (m *MergeGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.Merge.Template
+}
+
+func (m *MergeGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.Merge.Filters
+}
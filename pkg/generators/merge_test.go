@@ -0,0 +1,190 @@
+package generators
+
+import (
+	"testing"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMergeGenerate(t *testing.T) {
+
+	listGenerator := func(elements ...argoprojiov1alpha1.ListGeneratorElement) *argoprojiov1alpha1.ListGenerator {
+		return &argoprojiov1alpha1.ListGenerator{Elements: elements}
+	}
+
+	testCases := []struct {
+		name           string
+		baseGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		mergeKeys      []string
+		expectedErr    error
+		expectErr      bool
+		expected       []map[string]interface{}
+	}{
+		{
+			name: "happy flow - override matching parameter sets",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a"},
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-b", Url: "https://b"},
+				)},
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-b", Url: "https://b-override"},
+				)},
+			},
+			mergeKeys: []string{"cluster"},
+			expected: []map[string]interface{}{
+				{"cluster": "cluster-a", "url": "https://a"},
+				{"cluster": "cluster-b", "url": "https://b-override"},
+			},
+		},
+		{
+			name: "parameter sets that do not match the base are ignored",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a"},
+				)},
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-c", Url: "https://c"},
+				)},
+			},
+			mergeKeys: []string{"cluster"},
+			expected: []map[string]interface{}{
+				{"cluster": "cluster-a", "url": "https://a"},
+			},
+		},
+		{
+			name: "later generators take precedence",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a"},
+				)},
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a-1"},
+				)},
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a-2"},
+				)},
+			},
+			mergeKeys: []string{"cluster"},
+			expected: []map[string]interface{}{
+				{"cluster": "cluster-a", "url": "https://a-2"},
+			},
+		},
+		{
+			name: "parameter sets missing a merge key are not merged",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a"},
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-b", Url: "https://b"},
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-c", Url: "https://c", Values: map[string]string{"env": "prod"}},
+				)},
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-d", Url: "https://d"},
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-e", Url: "https://e", Values: map[string]string{"env": "prod"}},
+				)},
+			},
+			mergeKeys: []string{"values.env"},
+			expected: []map[string]interface{}{
+				{"cluster": "cluster-a", "url": "https://a"},
+				{"cluster": "cluster-b", "url": "https://b"},
+				{"cluster": "cluster-e", "url": "https://e", "values.env": "prod"},
+			},
+		},
+		{
+			name: "returns error if there is less than two base generators",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator()},
+			},
+			mergeKeys:   []string{"cluster"},
+			expectedErr: LessThanTwoGeneratorsInMergeError,
+		},
+		{
+			name: "returns error if there are no merge keys",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator()},
+				{List: listGenerator()},
+			},
+			expectedErr: NoMergeKeysError,
+		},
+		{
+			name: "returns error if the merge keys are not unique",
+			baseGenerators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{List: listGenerator(
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://a"},
+					argoprojiov1alpha1.ListGeneratorElement{Cluster: "cluster-a", Url: "https://b"},
+				)},
+				{List: listGenerator()},
+			},
+			mergeKeys: []string{"cluster"},
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase // Since tests may run in parallel
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			var mergeGenerator = NewMergeGenerator(
+				map[string]Generator{
+					"List": NewListGenerator(),
+				},
+			)
+
+			got, err := mergeGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Merge: &argoprojiov1alpha1.MergeGenerator{
+					Generators: testCaseCopy.baseGenerators,
+					MergeKeys:  testCaseCopy.mergeKeys,
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{})
+
+			if testCaseCopy.expectedErr != nil {
+				assert.EqualError(t, err, testCaseCopy.expectedErr.Error())
+			} else if testCaseCopy.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
+
+func TestMergeGetRequeueAfter(t *testing.T) {
+
+	gitGenerator := &argoprojiov1alpha1.GitGenerator{
+		RepoURL:             "RepoURL",
+		Revision:            "Revision",
+		Directories:         []argoprojiov1alpha1.GitDirectoryGeneratorItem{{Path: "*"}},
+		RequeueAfterSeconds: 30,
+	}
+
+	listGenerator := &argoprojiov1alpha1.ListGenerator{
+		Elements: []argoprojiov1alpha1.ListGeneratorElement{
+			{Cluster: "Cluster", Url: "Url"},
+		},
+	}
+
+	argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
+
+	var mergeGenerator = NewMergeGenerator(
+		map[string]Generator{
+			"Git":  NewGitGenerator(argoCDServiceMock),
+			"List": NewListGenerator(),
+		},
+	)
+
+	got := mergeGenerator.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		Merge: &argoprojiov1alpha1.MergeGenerator{
+			Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+				{Git: gitGenerator},
+				{List: listGenerator},
+			},
+			MergeKeys: []string{"path"},
+		},
+	})
+
+	assert.Equal(t, time.Duration(30)*time.Second, got)
+}
@@ -0,0 +1,146 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/services/plugin"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+// Keys of the ConfigMap describing a plugin
+const (
+	PluginBaseURLKey         = "baseUrl"
+	PluginTokenSecretNameKey = "tokenSecretName"
+	PluginTokenSecretKeyKey  = "tokenSecretKey"
+	PluginRequestTimeoutKey  = "requestTimeout"
+)
+
+// DefaultPluginRequeueAfterSeconds is the polling interval used when the generator doesn't set one
+const DefaultPluginRequeueAfterSeconds = 30 * time.Minute
+
+var _ Generator = (*PluginGenerator)(nil)
+
+// PluginGenerator generates parameters by querying an external HTTP service.
+type PluginGenerator struct {
+	client client.Client
+	// namespace is the namespace of the ConfigMaps and Secrets configuring the plugins
+	namespace string
+}
+
+func NewPluginGenerator(c client.Client, namespace string) Generator {
+	g := &PluginGenerator{
+		client:    c,
+		namespace: namespace,
+	}
+	return g
+}
+
+func (g *PluginGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	if appSetGenerator.Plugin.RequeueAfterSeconds != 0 {
+		return time.Duration(appSetGenerator.Plugin.RequeueAfterSeconds) * time.Second
+	}
+
+	return DefaultPluginRequeueAfterSeconds
+}
+
+func (g *PluginGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.Plugin.Template
+}
+
+func (g *PluginGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.Plugin.Filters
+}
+
+func (g *PluginGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.Plugin == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	ctx := context.Background()
+	generatorConfig := appSetGenerator.Plugin
+
+	svc, err := g.getPluginService(ctx, generatorConfig.ConfigMapRef)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := svc.List(ctx, plugin.Request{
+		ApplicationSetName: applicationSetInfo.Name,
+		Input:              plugin.RequestInput{Parameters: generatorConfig.Input.Parameters},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing params from plugin %s: %v", generatorConfig.ConfigMapRef, err)
+	}
+
+	res := make([]map[string]interface{}, 0, len(list))
+	for _, objectParams := range list {
+		params := make(map[string]interface{}, len(objectParams)+len(generatorConfig.Values))
+
+		// The Go template rendering uses the typed and nested parameters of the plugin as is, while the default
+		// rendering uses them flattened to string parameters
+		if applicationSetInfo.Spec.GoTemplate {
+			for key, value := range objectParams {
+				params[key] = value
+			}
+		} else {
+			flat, err := utils.FlattenParameters(objectParams)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range flat {
+				params[key] = value
+			}
+		}
+		for key, value := range generatorConfig.Values {
+			params[fmt.Sprintf("values.%s", key)] = value
+		}
+
+		res = append(res, params)
+	}
+
+	return res, nil
+}
+
+// getPluginService creates the service for the plugin described by the ConfigMap.
+func (g *PluginGenerator) getPluginService(ctx context.Context, configMapName string) (*plugin.Service, error) {
+	if configMapName == "" {
+		return nil, fmt.Errorf("configMapRef is required for the plugin generator")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := g.client.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: g.namespace}, configMap); err != nil {
+		return nil, fmt.Errorf("error reading ConfigMap %s/%s: %v", g.namespace, configMapName, err)
+	}
+
+	var token string
+	if secretName := configMap.Data[PluginTokenSecretNameKey]; secretName != "" {
+		var err error
+		token, err = getSecretRef(ctx, g.client, &argoprojiov1alpha1.SecretRef{SecretName: secretName, Key: configMap.Data[PluginTokenSecretKeyKey]}, g.namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching plugin token: %v", err)
+		}
+	}
+
+	var timeout time.Duration
+	if requestTimeout := configMap.Data[PluginRequestTimeoutKey]; requestTimeout != "" {
+		seconds, err := strconv.Atoi(requestTimeout)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid %s %q in ConfigMap %s/%s, expected a positive number of seconds", PluginRequestTimeoutKey, requestTimeout, g.namespace, configMapName)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	return plugin.NewPluginService(configMap.Data[PluginBaseURLKey], token, timeout)
+}
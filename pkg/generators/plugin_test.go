@@ -0,0 +1,154 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/services/plugin"
+)
+
+func TestPluginGenerateParams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var request plugin.Request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprintf(w, `[{"cluster": "prod-01", "env": %q, "app": {"replicas": 3}}]`, request.Input.Parameters["env"])
+	}))
+	defer ts.Close()
+
+	configMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "argocd"}, Data: data}
+	}
+
+	objects := []client.Object{
+		configMap("plugin", map[string]string{
+			"baseUrl":         ts.URL,
+			"tokenSecretName": "plugin-token",
+			"tokenSecretKey":  "token",
+			"requestTimeout":  "10",
+		}),
+		configMap("plugin-wrong-token", map[string]string{
+			"baseUrl":         ts.URL,
+			"tokenSecretName": "plugin-token",
+			"tokenSecretKey":  "other-token",
+		}),
+		configMap("plugin-missing-secret", map[string]string{
+			"baseUrl":         ts.URL,
+			"tokenSecretName": "missing",
+			"tokenSecretKey":  "token",
+		}),
+		configMap("plugin-invalid-timeout", map[string]string{
+			"baseUrl":        ts.URL,
+			"requestTimeout": "soon",
+		}),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "plugin-token", Namespace: "argocd"},
+			Data: map[string][]byte{
+				"token":       []byte("my-token"),
+				"other-token": []byte("other-token"),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		configMapRef  string
+		useGoTemplate bool
+		expected      []map[string]interface{}
+		expectedErr   bool
+	}{
+		{
+			name:         "happy flow",
+			configMapRef: "plugin",
+			expected: []map[string]interface{}{
+				{"cluster": "prod-01", "env": "production", "app.replicas": "3", "values.foo": "bar"},
+			},
+		},
+		{
+			name:          "typed parameters are kept for go templates",
+			configMapRef:  "plugin",
+			useGoTemplate: true,
+			expected: []map[string]interface{}{
+				{"cluster": "prod-01", "env": "production", "app": map[string]interface{}{"replicas": float64(3)}, "values.foo": "bar"},
+			},
+		},
+		{
+			name:         "plugin rejects the token",
+			configMapRef: "plugin-wrong-token",
+			expectedErr:  true,
+		},
+		{
+			name:         "missing token secret",
+			configMapRef: "plugin-missing-secret",
+			expectedErr:  true,
+		},
+		{
+			name:         "invalid request timeout",
+			configMapRef: "plugin-invalid-timeout",
+			expectedErr:  true,
+		},
+		{
+			name:         "missing configmap",
+			configMapRef: "missing",
+			expectedErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+			var pluginGenerator = NewPluginGenerator(fakeClient, "argocd")
+
+			got, err := pluginGenerator.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				Plugin: &argoprojiov1alpha1.PluginGenerator{
+					ConfigMapRef: testCaseCopy.configMapRef,
+					Input:        argoprojiov1alpha1.PluginInput{Parameters: map[string]string{"env": "production"}},
+					Values:       map[string]string{"foo": "bar"},
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "appset", Namespace: "argocd"},
+				Spec:       argoprojiov1alpha1.ApplicationSetSpec{GoTemplate: testCaseCopy.useGoTemplate},
+			})
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCaseCopy.expected, got)
+			}
+		})
+	}
+}
+
+func TestPluginGetRequeueAfter(t *testing.T) {
+	gen := NewPluginGenerator(nil, "argocd")
+
+	assert.Equal(t, DefaultPluginRequeueAfterSeconds, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		Plugin: &argoprojiov1alpha1.PluginGenerator{},
+	}))
+	assert.Equal(t, 30*time.Second, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		Plugin: &argoprojiov1alpha1.PluginGenerator{RequeueAfterSeconds: 30},
+	}))
+}
@@ -0,0 +1,158 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	pullrequest "github.com/argoproj-labs/applicationset/pkg/services/pull_request"
+)
+
+// DefaultPullRequestRequeueAfter is the polling interval used when the generator doesn't set one
+const DefaultPullRequestRequeueAfter = 30 * time.Minute
+
+var _ Generator = (*PullRequestGenerator)(nil)
+
+// PullRequestGenerator generates parameters for the open pull requests of a repository.
+type PullRequestGenerator struct {
+	client client.Client
+	// selectServiceProviderFunc returns the PullRequestService for the generator, it is overridden in tests
+	selectServiceProviderFunc func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error)
+}
+
+func NewPullRequestGenerator(c client.Client) Generator {
+	g := &PullRequestGenerator{
+		client: c,
+	}
+	g.selectServiceProviderFunc = g.selectServiceProvider
+	return g
+}
+
+func (g *PullRequestGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	if appSetGenerator.PullRequest.RequeueAfterSeconds != 0 {
+		return time.Duration(appSetGenerator.PullRequest.RequeueAfterSeconds) * time.Second
+	}
+
+	return DefaultPullRequestRequeueAfter
+}
+
+func (g *PullRequestGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.PullRequest.Template
+}
+
+func (g *PullRequestGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return appSetGenerator.PullRequest.Filters
+}
+
+func (g *PullRequestGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.PullRequest == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	ctx := context.Background()
+	svc, err := g.selectServiceProviderFunc(ctx, appSetGenerator.PullRequest, applicationSetInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select pull request service provider: %v", err)
+	}
+
+	pulls, err := svc.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests for ApplicationSet %s: %v", applicationSetInfo.Name, err)
+	}
+
+	params := make([]map[string]interface{}, 0, len(pulls))
+	for _, pull := range pulls {
+		shortSHA := pull.HeadSHA
+		if len(shortSHA) > 8 {
+			shortSHA = shortSHA[:8]
+		}
+		// The labels are a list, flattened to labels.0, labels.1, etc. without Go templates
+		labels := make([]interface{}, 0, len(pull.Labels))
+		for _, label := range pull.Labels {
+			labels = append(labels, label)
+		}
+		params = append(params, map[string]interface{}{
+			"number":         strconv.Itoa(pull.Number),
+			"branch":         pull.Branch,
+			"branch_slug":    slugify(pull.Branch),
+			"head_sha":       pull.HeadSHA,
+			"head_short_sha": shortSHA,
+			"labels":         labels,
+		})
+	}
+
+	return params, nil
+}
+
+// selectServiceProvider selects the provider to get pull requests from the configuration
+func (g *PullRequestGenerator) selectServiceProvider(ctx context.Context, generatorConfig *argoprojiov1alpha1.PullRequestGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
+	if generatorConfig.Github != nil {
+		providerConfig := generatorConfig.Github
+		token, err := getSecretRef(ctx, g.client, providerConfig.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Secret token: %v", err)
+		}
+		return pullrequest.NewGithubService(token, providerConfig.API, providerConfig.Owner, providerConfig.Repo, providerConfig.Labels)
+	}
+	if generatorConfig.GitLab != nil {
+		providerConfig := generatorConfig.GitLab
+		token, err := getSecretRef(ctx, g.client, providerConfig.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Secret token: %v", err)
+		}
+		return pullrequest.NewGitLabService(token, providerConfig.API, providerConfig.Project, providerConfig.Labels)
+	}
+	if generatorConfig.Gitea != nil {
+		providerConfig := generatorConfig.Gitea
+		token, err := getSecretRef(ctx, g.client, providerConfig.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Secret token: %v", err)
+		}
+		return pullrequest.NewGiteaService(token, providerConfig.API, providerConfig.Owner, providerConfig.Repo, providerConfig.Insecure, providerConfig.Labels)
+	}
+	return nil, fmt.Errorf("no Pull Request provider implementation configured")
+}
+
+// getSecretRef returns the value of the referenced Secret key, read from the given namespace. A nil ref returns
+// an empty value, meaning no authentication is used.
+func getSecretRef(ctx context.Context, k8sClient client.Client, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	err := k8sClient.Get(ctx, client.ObjectKey{Name: ref.SecretName, Namespace: namespace}, secret)
+	if err != nil {
+		return "", fmt.Errorf("error fetching secret %s/%s: %v", namespace, ref.SecretName, err)
+	}
+
+	tokenBytes, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q in secret %s/%s not found", ref.Key, namespace, ref.SecretName)
+	}
+
+	return string(tokenBytes), nil
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a branch name into a value usable in resource names: lowercase alphanumerics and dashes,
+// at most 50 characters long.
+func slugify(branch string) string {
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(branch), "-")
+	if len(slug) > 50 {
+		slug = slug[:50]
+	}
+	return strings.Trim(slug, "-")
+}
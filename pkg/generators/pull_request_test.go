@@ -0,0 +1,129 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	pullrequest "github.com/argoproj-labs/applicationset/pkg/services/pull_request"
+)
+
+func TestPullRequestGenerateParams(t *testing.T) {
+
+	cases := []struct {
+		selectFunc  func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error)
+		expected    []map[string]interface{}
+		expectedErr error
+	}{
+		{
+			selectFunc: func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
+				return &pullrequest.FakeService{
+					PullRequests: []*pullrequest.PullRequest{
+						{
+							Number:  1,
+							Branch:  "Feature/Branch_1",
+							HeadSHA: "089d92cbf9ff857a39e6feccd32798ca700fb958",
+							Labels:  []string{"preview", "team-a"},
+						},
+					},
+				}, nil
+			},
+			expected: []map[string]interface{}{
+				{
+					"number":         "1",
+					"branch":         "Feature/Branch_1",
+					"branch_slug":    "feature-branch-1",
+					"head_sha":       "089d92cbf9ff857a39e6feccd32798ca700fb958",
+					"head_short_sha": "089d92cb",
+					"labels":         []interface{}{"preview", "team-a"},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			selectFunc: func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
+				return &pullrequest.FakeService{
+					PullRequests: []*pullrequest.PullRequest{},
+				}, nil
+			},
+			expected:    []map[string]interface{}{},
+			expectedErr: nil,
+		},
+		{
+			selectFunc: func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
+				return &pullrequest.FakeService{
+					Err: errors.New("fake error"),
+				}, nil
+			},
+			expected:    nil,
+			expectedErr: errors.New("error listing pull requests for ApplicationSet appset: fake error"),
+		},
+	}
+
+	for _, c := range cases {
+		gen := PullRequestGenerator{
+			selectServiceProviderFunc: c.selectFunc,
+		}
+
+		got, gotErr := gen.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+			PullRequest: &argoprojiov1alpha1.PullRequestGenerator{},
+		}, &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "appset"}})
+
+		if c.expectedErr != nil {
+			assert.EqualError(t, gotErr, c.expectedErr.Error())
+		} else {
+			assert.NoError(t, gotErr)
+		}
+		assert.ElementsMatch(t, c.expected, got)
+	}
+}
+
+func TestPullRequestSelectServiceProvider(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "scm-token", Namespace: "argocd"},
+		Data:       map[string][]byte{"token": []byte("my-token")},
+	}
+	fakeClient := fake.NewClientBuilder().WithObjects([]client.Object{secret}...).Build()
+	gen := NewPullRequestGenerator(fakeClient).(*PullRequestGenerator)
+	appSet := &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "appset", Namespace: "argocd"}}
+
+	svc, err := gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		Github: &argoprojiov1alpha1.PullRequestGeneratorGithub{
+			Owner:    "argoproj-labs",
+			Repo:     "applicationset",
+			TokenRef: &argoprojiov1alpha1.SecretRef{SecretName: "scm-token", Key: "token"},
+		},
+	}, appSet)
+	assert.NoError(t, err)
+	assert.IsType(t, &pullrequest.GithubService{}, svc)
+
+	_, err = gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		GitLab: &argoprojiov1alpha1.PullRequestGeneratorGitLab{
+			Project:  "group/project",
+			TokenRef: &argoprojiov1alpha1.SecretRef{SecretName: "scm-token", Key: "missing"},
+		},
+	}, appSet)
+	assert.Error(t, err)
+
+	_, err = gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{}, appSet)
+	assert.Error(t, err)
+}
+
+func TestPullRequestGetRequeueAfter(t *testing.T) {
+	gen := NewPullRequestGenerator(nil)
+
+	assert.Equal(t, DefaultPullRequestRequeueAfter, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		PullRequest: &argoprojiov1alpha1.PullRequestGenerator{},
+	}))
+	assert.Equal(t, 60*time.Second, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		PullRequest: &argoprojiov1alpha1.PullRequestGenerator{RequeueAfterSeconds: 60},
+	}))
+}
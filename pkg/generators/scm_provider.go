@@ -0,0 +1,221 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/expr"
+	"github.com/argoproj-labs/applicationset/pkg/services"
+	"github.com/argoproj-labs/applicationset/pkg/services/scm_provider"
+)
+
+// DefaultSCMProviderRequeueAfterSeconds is the polling interval used when the generator doesn't set one
+const DefaultSCMProviderRequeueAfterSeconds = 30 * time.Minute
+
+var _ Generator = (*SCMProviderGenerator)(nil)
+
+// SCMProviderGenerator generates parameters for the repositories discovered in an organization of an SCM provider.
+type SCMProviderGenerator struct {
+	client client.Client
+	// repos is used to check the existence of paths in the discovered repositories
+	repos services.Repos
+	// overrideProvider replaces the configured provider, it is used in tests
+	overrideProvider scm_provider.SCMProviderService
+}
+
+func NewSCMProviderGenerator(c client.Client, repos services.Repos) Generator {
+	g := &SCMProviderGenerator{
+		client: c,
+		repos:  repos,
+	}
+	return g
+}
+
+func (g *SCMProviderGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
+	if appSetGenerator.SCMProvider.RequeueAfterSeconds != 0 {
+		return time.Duration(appSetGenerator.SCMProvider.RequeueAfterSeconds) * time.Second
+	}
+
+	return DefaultSCMProviderRequeueAfterSeconds
+}
+
+func (g *SCMProviderGenerator) GetTemplate(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) *argoprojiov1alpha1.ApplicationSetTemplate {
+	return &appSetGenerator.SCMProvider.Template
+}
+
+// GetFilters returns no filters: the filter expressions of the SCM provider generator are part of its own filters,
+// which are evaluated per repository by GenerateParams.
+func (g *SCMProviderGenerator) GetFilters(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) []argoprojiov1alpha1.GeneratorFilter {
+	return nil
+}
+
+func (g *SCMProviderGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) ([]map[string]interface{}, error) {
+	if appSetGenerator == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	if appSetGenerator.SCMProvider == nil {
+		return nil, EmptyAppSetGeneratorError
+	}
+
+	ctx := context.Background()
+	providerConfig := appSetGenerator.SCMProvider
+
+	filters, err := compileSCMProviderFilters(providerConfig.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := g.selectProvider(ctx, providerConfig, applicationSetInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := provider.ListRepos(ctx, providerConfig.CloneProtocol)
+	if err != nil {
+		return nil, fmt.Errorf("error listing repos: %v", err)
+	}
+
+	params := make([]map[string]interface{}, 0, len(repos))
+	for _, repo := range repos {
+		repoParams := map[string]interface{}{
+			"organization": repo.Organization,
+			"repository":   repo.Repository,
+			"url":          repo.URL,
+			"branch":       repo.Branch,
+			"sha":          repo.SHA,
+		}
+
+		matched, err := g.matchesFilters(ctx, repo, repoParams, filters)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		params = append(params, repoParams)
+	}
+
+	log.WithField("repos", len(repos)).WithField("matched", len(params)).Debug("scm provider discovered repositories")
+
+	return params, nil
+}
+
+// selectProvider creates the SCMProviderService for the configured SCM provider.
+func (g *SCMProviderGenerator) selectProvider(ctx context.Context, providerConfig *argoprojiov1alpha1.SCMProviderGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) (scm_provider.SCMProviderService, error) {
+	if g.overrideProvider != nil {
+		return g.overrideProvider, nil
+	}
+
+	if providerConfig.Github != nil {
+		token, err := getSecretRef(ctx, g.client, providerConfig.Github.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Github token: %v", err)
+		}
+		return scm_provider.NewGithubProvider(token, providerConfig.Github.API, providerConfig.Github.Organization, providerConfig.Github.AllBranches)
+	}
+	if providerConfig.Gitlab != nil {
+		token, err := getSecretRef(ctx, g.client, providerConfig.Gitlab.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Gitlab token: %v", err)
+		}
+		return scm_provider.NewGitlabProvider(token, providerConfig.Gitlab.API, providerConfig.Gitlab.Group, providerConfig.Gitlab.IncludeSubgroups, providerConfig.Gitlab.AllBranches)
+	}
+	if providerConfig.Gitea != nil {
+		token, err := getSecretRef(ctx, g.client, providerConfig.Gitea.TokenRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Gitea token: %v", err)
+		}
+		return scm_provider.NewGiteaProvider(token, providerConfig.Gitea.API, providerConfig.Gitea.Owner, providerConfig.Gitea.Insecure, providerConfig.Gitea.AllBranches)
+	}
+	return nil, fmt.Errorf("no SCM provider implementation configured")
+}
+
+// scmProviderFilter is a SCMProviderGeneratorFilter with its regular expressions compiled.
+type scmProviderFilter struct {
+	repositoryMatch *regexp.Regexp
+	branchMatch     *regexp.Regexp
+	expr            *expr.Program
+	pathsExist      []string
+}
+
+func compileSCMProviderFilters(filters []argoprojiov1alpha1.SCMProviderGeneratorFilter) ([]scmProviderFilter, error) {
+	res := make([]scmProviderFilter, 0, len(filters))
+	for _, filter := range filters {
+		compiled := scmProviderFilter{pathsExist: filter.PathsExist}
+		if filter.RepositoryMatch != nil {
+			re, err := regexp.Compile(*filter.RepositoryMatch)
+			if err != nil {
+				return nil, fmt.Errorf("error compiling RepositoryMatch regexp %q: %v", *filter.RepositoryMatch, err)
+			}
+			compiled.repositoryMatch = re
+		}
+		if filter.BranchMatch != nil {
+			re, err := regexp.Compile(*filter.BranchMatch)
+			if err != nil {
+				return nil, fmt.Errorf("error compiling BranchMatch regexp %q: %v", *filter.BranchMatch, err)
+			}
+			compiled.branchMatch = re
+		}
+		if filter.Expr != nil {
+			program, err := expr.Compile(*filter.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter expression %q: %v", *filter.Expr, err)
+			}
+			compiled.expr = program
+		}
+		res = append(res, compiled)
+	}
+	return res, nil
+}
+
+// matchesFilters returns true if the repository matches any of the filters, or if there are no filters.
+// The cheap name and expression based conditions are evaluated before the paths, which require a checkout of the
+// repository.
+func (g *SCMProviderGenerator) matchesFilters(ctx context.Context, repo *scm_provider.Repository, params map[string]interface{}, filters []scmProviderFilter) (bool, error) {
+	if len(filters) == 0 {
+		return true, nil
+	}
+
+	for _, filter := range filters {
+		if filter.repositoryMatch != nil && !filter.repositoryMatch.MatchString(repo.Repository) {
+			continue
+		}
+		if filter.branchMatch != nil && !filter.branchMatch.MatchString(repo.Branch) {
+			continue
+		}
+		if filter.expr != nil {
+			matched, err := filter.expr.Match(params)
+			if err != nil {
+				return false, fmt.Errorf("error evaluating filter expression %q: %v", filter.expr, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		pathsExist := true
+		for _, path := range filter.pathsExist {
+			paths, err := g.repos.GetPaths(ctx, repo.URL, repo.SHA, path)
+			if err != nil {
+				return false, fmt.Errorf("error checking path %s in %s/%s: %v", path, repo.Organization, repo.Repository, err)
+			}
+			if len(paths) == 0 {
+				pathsExist = false
+				break
+			}
+		}
+		if pathsExist {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,134 @@
+package generators
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/services/scm_provider"
+)
+
+func strp(s string) *string {
+	return &s
+}
+
+func TestSCMProviderGenerateParams(t *testing.T) {
+	repos := []*scm_provider.Repository{
+		{Organization: "myorg", Repository: "repo1", URL: "git@github.com:myorg/repo1.git", Branch: "main", SHA: "0bc57212c3cbbec69d20b34c507284bd300def5b"},
+		{Organization: "myorg", Repository: "repo2", URL: "git@github.com:myorg/repo2.git", Branch: "main", SHA: "59d0de3e6a7d6bbb1d03d5e2dc1e1e8c2a3a2b52"},
+		{Organization: "myorg", Repository: "repo2", URL: "git@github.com:myorg/repo2.git", Branch: "feature", SHA: "7d6e3bde2b8a7d4f9ad0de5a6e76c7d6c8f1d6a1"},
+	}
+
+	cases := []struct {
+		name        string
+		filters     []argoprojiov1alpha1.SCMProviderGeneratorFilter
+		repoPaths   map[string][]string
+		providerErr error
+		expected    []map[string]interface{}
+		expectedErr bool
+	}{
+		{
+			name: "no filters returns every repository",
+			expected: []map[string]interface{}{
+				{"organization": "myorg", "repository": "repo1", "url": "git@github.com:myorg/repo1.git", "branch": "main", "sha": "0bc57212c3cbbec69d20b34c507284bd300def5b"},
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "main", "sha": "59d0de3e6a7d6bbb1d03d5e2dc1e1e8c2a3a2b52"},
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "feature", "sha": "7d6e3bde2b8a7d4f9ad0de5a6e76c7d6c8f1d6a1"},
+			},
+		},
+		{
+			name:    "repository and branch match are combined",
+			filters: []argoprojiov1alpha1.SCMProviderGeneratorFilter{{RepositoryMatch: strp("2$"), BranchMatch: strp("^feat")}},
+			expected: []map[string]interface{}{
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "feature", "sha": "7d6e3bde2b8a7d4f9ad0de5a6e76c7d6c8f1d6a1"},
+			},
+		},
+		{
+			name: "any of the filters may match",
+			filters: []argoprojiov1alpha1.SCMProviderGeneratorFilter{
+				{RepositoryMatch: strp("^repo1$")},
+				{BranchMatch: strp("^feature$")},
+			},
+			expected: []map[string]interface{}{
+				{"organization": "myorg", "repository": "repo1", "url": "git@github.com:myorg/repo1.git", "branch": "main", "sha": "0bc57212c3cbbec69d20b34c507284bd300def5b"},
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "feature", "sha": "7d6e3bde2b8a7d4f9ad0de5a6e76c7d6c8f1d6a1"},
+			},
+		},
+		{
+			name:    "paths must exist",
+			filters: []argoprojiov1alpha1.SCMProviderGeneratorFilter{{BranchMatch: strp("^main$"), PathsExist: []string{"kustomization.yaml"}}},
+			repoPaths: map[string][]string{
+				"git@github.com:myorg/repo1.git": {},
+				"git@github.com:myorg/repo2.git": {"kustomization.yaml"},
+			},
+			expected: []map[string]interface{}{
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "main", "sha": "59d0de3e6a7d6bbb1d03d5e2dc1e1e8c2a3a2b52"},
+			},
+		},
+		{
+			name:    "expressions are evaluated against the repository parameters",
+			filters: []argoprojiov1alpha1.SCMProviderGeneratorFilter{{Expr: strp(`{{repository}} == "repo2" && {{branch}} != "main"`)}},
+			expected: []map[string]interface{}{
+				{"organization": "myorg", "repository": "repo2", "url": "git@github.com:myorg/repo2.git", "branch": "feature", "sha": "7d6e3bde2b8a7d4f9ad0de5a6e76c7d6c8f1d6a1"},
+			},
+		},
+		{
+			name:        "invalid expression",
+			filters:     []argoprojiov1alpha1.SCMProviderGeneratorFilter{{Expr: strp(`{{repository}} ==`)}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid regexp",
+			filters:     []argoprojiov1alpha1.SCMProviderGeneratorFilter{{RepositoryMatch: strp("(")}},
+			expectedErr: true,
+		},
+		{
+			name:        "provider error",
+			providerErr: errors.New("provider error"),
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		cc := c
+		t.Run(cc.name, func(t *testing.T) {
+			argoCDServiceMock := argoCDServiceMock{mock: &mock.Mock{}}
+			for url, paths := range cc.repoPaths {
+				argoCDServiceMock.mock.On("GetPaths", mock.Anything, url, mock.Anything, "kustomization.yaml").Return(paths, nil)
+			}
+
+			gen := &SCMProviderGenerator{
+				repos:            argoCDServiceMock,
+				overrideProvider: &scm_provider.FakeProvider{Repos: repos, Err: cc.providerErr},
+			}
+
+			got, err := gen.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{
+				SCMProvider: &argoprojiov1alpha1.SCMProviderGenerator{
+					Filters: cc.filters,
+				},
+			}, &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "appset", Namespace: "argocd"}})
+
+			if cc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, cc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSCMProviderGetRequeueAfter(t *testing.T) {
+	gen := NewSCMProviderGenerator(nil, nil)
+
+	assert.Equal(t, DefaultSCMProviderRequeueAfterSeconds, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		SCMProvider: &argoprojiov1alpha1.SCMProviderGenerator{},
+	}))
+	assert.Equal(t, 120*time.Second, gen.GetRequeueAfter(&argoprojiov1alpha1.ApplicationSetGenerator{
+		SCMProvider: &argoprojiov1alpha1.SCMProviderGenerator{RequeueAfterSeconds: 120},
+	}))
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// appManifestFiles are the files marking a directory as an application, as discovered by the Argo CD repo server:
+// Helm charts and Kustomizations.
+var appManifestFiles = map[string]bool{
+	"Chart.yaml":         true,
+	"kustomization.yaml": true,
+	"kustomization.yml":  true,
+	"Kustomization":      true,
+}
+
+type localRepos struct {
+	// roots maps the normalized repository URLs to the directories of their local checkouts
+	roots map[string]string
+}
+
+// NewLocalRepos returns a Repos reading the local checkouts of the repositories, e.g. to render ApplicationSets without
+// a cluster. roots maps the repository URLs to the directories of their checkouts, the empty URL being the checkout of
+// the repositories not listed. The revisions are ignored: the checkouts are read as they are.
+func NewLocalRepos(roots map[string]string) Repos {
+	res := &localRepos{roots: map[string]string{}}
+	for repoURL, root := range roots {
+		res.roots[normalizeLocalRepoURL(repoURL)] = root
+	}
+	return res
+}
+
+// ResolveRevision returns the revision as is, since the checkouts are read as they are.
+func (l *localRepos) ResolveRevision(ctx context.Context, repoURL string, revision string) (string, error) {
+	return revision, nil
+}
+
+func (l *localRepos) GetApps(ctx context.Context, repoURL string, revision string) ([]string, error) {
+	root, err := l.getRoot(repoURL, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := map[string]bool{}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if appManifestFiles[info.Name()] {
+			dir, err := filepath.Rel(root, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			apps[filepath.ToSlash(dir)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for app := range apps {
+		res = append(res, app)
+	}
+	return res, nil
+}
+
+func (l *localRepos) GetDirectories(ctx context.Context, repoURL string, revision string) ([]string, error) {
+	root, err := l.getRoot(repoURL, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return listDirectories(root)
+}
+
+// GetPaths returns the files matching the pattern as a git pathspec, like git ls-files does for the Argo CD repo
+// server: its wildcards also match slashes.
+func (l *localRepos) GetPaths(ctx context.Context, repoURL string, revision string, pattern string) ([]string, error) {
+	root, err := l.getRoot(repoURL, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	pathspec, err := compileGitPathspec(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath = filepath.ToSlash(relPath); pathspec.MatchString(relPath) {
+			res = append(res, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// compileGitPathspec returns the regular expression of a git pathspec: "*" matches any characters, slashes included,
+// "?" any character, and "[...]" a character class.
+func compileGitPathspec(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid pattern %q: unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+func (l *localRepos) GetFileContent(ctx context.Context, repoURL string, revision string, path string) ([]byte, error) {
+	root, err := l.getRoot(repoURL, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+}
+
+// getRoot returns the directory of the checkout of the repository.
+func (l *localRepos) getRoot(repoURL string, revision string) (string, error) {
+	root, found := l.roots[normalizeLocalRepoURL(repoURL)]
+	if !found {
+		root, found = l.roots[""]
+	}
+	if !found {
+		return "", fmt.Errorf("no local checkout of repository %s", repoURL)
+	}
+
+	log.WithField("repoURL", repoURL).WithField("revision", revision).Debugf("reading local checkout %s", root)
+	return root, nil
+}
+
+func normalizeLocalRepoURL(repoURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(repoURL), "/"), ".git")
+}
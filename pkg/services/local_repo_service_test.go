@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalRepos(t *testing.T) {
+	root, err := ioutil.TempDir("", "local-repos")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	for path, content := range map[string]string{
+		"apps/guestbook/kustomization.yaml": "",
+		"apps/helm-guestbook/Chart.yaml":    "",
+		"apps/plain/deployment.yaml":        "",
+		"cluster-config/dev/config.json":    `{"name": "dev"}`,
+		"cluster-config/prod/config.json":   `{"name": "prod"}`,
+		".git/kustomization.yaml":           "",
+	} {
+		err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755)
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(filepath.Join(root, path), []byte(content), 0644)
+		assert.Nil(t, err)
+	}
+
+	repos := NewLocalRepos(map[string]string{"https://github.com/argoproj/argocd-example-apps.git": root})
+
+	apps, err := repos.GetApps(context.TODO(), "https://github.com/argoproj/argocd-example-apps/", "HEAD")
+	assert.Nil(t, err)
+	sort.Strings(apps)
+	assert.Equal(t, []string{"apps/guestbook", "apps/helm-guestbook"}, apps)
+
+	dirs, err := repos.GetDirectories(context.TODO(), "https://github.com/argoproj/argocd-example-apps", "HEAD")
+	assert.Nil(t, err)
+	sort.Strings(dirs)
+	assert.Equal(t, []string{"apps", "apps/guestbook", "apps/helm-guestbook", "apps/plain", "cluster-config", "cluster-config/dev", "cluster-config/prod"}, dirs)
+
+	paths, err := repos.GetPaths(context.TODO(), "https://github.com/argoproj/argocd-example-apps", "HEAD", "cluster-config/*/config.json")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cluster-config/dev/config.json", "cluster-config/prod/config.json"}, paths)
+
+	// Like git ls-files, the wildcards match slashes
+	paths, err = repos.GetPaths(context.TODO(), "https://github.com/argoproj/argocd-example-apps", "HEAD", "*/Chart.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"apps/helm-guestbook/Chart.yaml"}, paths)
+
+	content, err := repos.GetFileContent(context.TODO(), "https://github.com/argoproj/argocd-example-apps", "HEAD", "cluster-config/dev/config.json")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "dev"}`, string(content))
+
+	_, err = repos.GetApps(context.TODO(), "https://github.com/argoproj/other", "HEAD")
+	assert.EqualError(t, err, "no local checkout of repository https://github.com/argoproj/other")
+
+	repos = NewLocalRepos(map[string]string{"": root})
+	paths, err = repos.GetPaths(context.TODO(), "https://github.com/argoproj/other", "HEAD", "cluster-config/prod/*.json")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cluster-config/prod/config.json"}, paths)
+}
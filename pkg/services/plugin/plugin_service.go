@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout is used when the plugin doesn't configure a request timeout
+	DefaultRequestTimeout = 30 * time.Second
+	// MaxResponseSize is the maximum size of a plugin response, larger responses are rejected
+	MaxResponseSize = 4 * 1024 * 1024
+
+	getParamsPath = "/api/v1/getparams.execute"
+)
+
+// Request is the body POSTed to a plugin.
+type Request struct {
+	// ApplicationSetName is the name of the ApplicationSet the plugin is queried for
+	ApplicationSetName string `json:"applicationSetName"`
+	// Input is the input configured on the generator
+	Input RequestInput `json:"input"`
+}
+
+// RequestInput is the input configured on the generator.
+type RequestInput struct {
+	Parameters map[string]string `json:"parameters"`
+}
+
+// Service queries a plugin for parameters.
+type Service struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewPluginService returns a service querying the plugin at baseURL, authenticating with the given bearer token.
+// A zero timeout uses DefaultRequestTimeout.
+func NewPluginService(baseURL string, token string, timeout time.Duration) (*Service, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("baseUrl is required for the plugin generator")
+	}
+	if timeout == 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return &Service{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+	}, nil
+}
+
+// List POSTs the request to the plugin, and returns the list of parameter objects it responds with.
+func (s *Service) List(ctx context.Context, request Request) ([]map[string]interface{}, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.baseURL + getParamsPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error querying plugin at %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	// Read one more byte than allowed, to tell a response of exactly MaxResponseSize from a larger one
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading plugin response from %s: %v", url, err)
+	}
+	if len(respBody) > MaxResponseSize {
+		return nil, fmt.Errorf("plugin response from %s exceeds the maximum size of %d bytes", url, MaxResponseSize)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if len(respBody) > 1024 {
+			respBody = respBody[:1024]
+		}
+		return nil, fmt.Errorf("unexpected status %d from plugin at %s: %s", resp.StatusCode, url, string(respBody))
+	}
+
+	var params []map[string]interface{}
+	if err := json.Unmarshal(respBody, &params); err != nil {
+		return nil, fmt.Errorf("plugin at %s must respond with a JSON list of parameter objects: %v", url, err)
+	}
+
+	return params, nil
+}
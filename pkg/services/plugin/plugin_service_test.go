@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/getparams.execute", r.URL.Path)
+		assert.Equal(t, "Bearer my-token", r.Header.Get("Authorization"))
+
+		var request Request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		assert.NoError(t, err)
+		assert.Equal(t, Request{ApplicationSetName: "appset", Input: RequestInput{Parameters: map[string]string{"env": "prod"}}}, request)
+
+		fmt.Fprint(w, `[{"cluster": "prod-01", "replicas": 3}, {"cluster": "prod-02", "replicas": 5}]`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewPluginService(ts.URL, "my-token", 0)
+	assert.NoError(t, err)
+
+	params, err := svc.List(context.Background(), Request{ApplicationSetName: "appset", Input: RequestInput{Parameters: map[string]string{"env": "prod"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"cluster": "prod-01", "replicas": float64(3)},
+		{"cluster": "prod-02", "replicas": float64(5)},
+	}, params)
+}
+
+func TestPluginListErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+		timeout time.Duration
+		errText string
+	}{
+		{
+			name: "unexpected status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, "forbidden")
+			},
+			errText: "unexpected status 403",
+		},
+		{
+			name: "not a list",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"cluster": "prod-01"}`)
+			},
+			errText: "must respond with a JSON list",
+		},
+		{
+			name: "response too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `[{"padding": "%s"}]`, strings.Repeat("a", MaxResponseSize))
+			},
+			errText: "exceeds the maximum size",
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				fmt.Fprint(w, `[]`)
+			},
+			timeout: 50 * time.Millisecond,
+			errText: "error querying plugin",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			ts := httptest.NewServer(testCaseCopy.handler)
+			defer ts.Close()
+
+			svc, err := NewPluginService(ts.URL, "", testCaseCopy.timeout)
+			assert.NoError(t, err)
+
+			_, err = svc.List(context.Background(), Request{ApplicationSetName: "appset"})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), testCaseCopy.errText)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package pull_request
+
+import (
+	"context"
+)
+
+// FakeService is a PullRequestService returning a fixed list of pull requests, for use in tests.
+type FakeService struct {
+	PullRequests []*PullRequest
+	Err          error
+}
+
+var _ PullRequestService = (*FakeService)(nil)
+
+func (f *FakeService) List(ctx context.Context) ([]*PullRequest, error) {
+	return f.PullRequests, f.Err
+}
@@ -0,0 +1,84 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const giteaPageSize = 50
+
+// GiteaService lists the open pull requests of a Gitea repository.
+type GiteaService struct {
+	client *http.Client
+	api    string
+	token  string
+	owner  string
+	repo   string
+	labels []string
+}
+
+var _ PullRequestService = (*GiteaService)(nil)
+
+// NewGiteaService returns a service for the given Gitea repository. An empty token uses anonymous access.
+func NewGiteaService(token, api, owner, repo string, insecure bool, labels []string) (PullRequestService, error) {
+	if api == "" {
+		return nil, fmt.Errorf("api is required for the Gitea pull request generator")
+	}
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("both owner and repo are required for the Gitea pull request generator")
+	}
+	return &GiteaService{
+		client: utils.NewHTTPClient(insecure),
+		api:    strings.TrimSuffix(api, "/"),
+		token:  token,
+		owner:  owner,
+		repo:   repo,
+		labels: labels,
+	}, nil
+}
+
+func (g *GiteaService) List(ctx context.Context) ([]*PullRequest, error) {
+	headers := map[string]string{}
+	if g.token != "" {
+		headers["Authorization"] = "token " + g.token
+	}
+
+	pullRequests := []*PullRequest{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/api/v1/repos/%s/%s/pulls?state=open&limit=%d&page=%d",
+			g.api, url.PathEscape(g.owner), url.PathEscape(g.repo), giteaPageSize, page)
+
+		// Gitea's pull request representation is compatible with GitHub's
+		var pulls []githubPullRequest
+		if _, err := utils.GetJSON(ctx, g.client, u, headers, &pulls); err != nil {
+			return nil, fmt.Errorf("error listing pull requests for %s/%s: %v", g.owner, g.repo, err)
+		}
+
+		for _, pull := range pulls {
+			labels := make([]string, 0, len(pull.Labels))
+			for _, label := range pull.Labels {
+				labels = append(labels, label.Name)
+			}
+			if !containLabels(g.labels, labels) {
+				continue
+			}
+			pullRequests = append(pullRequests, &PullRequest{
+				Number:  pull.Number,
+				Branch:  pull.Head.Ref,
+				HeadSHA: pull.Head.SHA,
+				Labels:  labels,
+			})
+		}
+
+		if len(pulls) < giteaPageSize {
+			break
+		}
+	}
+
+	return pullRequests, nil
+}
@@ -0,0 +1,49 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteaList(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/repos/test-argocd/pr-test/pulls", r.URL.Path)
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "token my-token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "head": {"ref": "test", "sha": "7bbaf62d92ddfafd9cc8b340c619abaec32bc09f"}, "labels": [{"name": "preview"}]},
+			{"number": 2, "head": {"ref": "other", "sha": "de0a5dfb7a3b16c4d3a2b4f4a91e0e4e4b6a5d3f"}, "labels": []}
+		]`)
+	}))
+	defer ts.Close()
+
+	// The test server uses a self-signed certificate
+	svc, err := NewGiteaService("my-token", ts.URL, "test-argocd", "pr-test", true, []string{"preview"})
+	assert.NoError(t, err)
+
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{
+		{Number: 1, Branch: "test", HeadSHA: "7bbaf62d92ddfafd9cc8b340c619abaec32bc09f", Labels: []string{"preview"}},
+	}, pullRequests)
+}
+
+func TestGiteaListSelfSignedCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGiteaService("", ts.URL, "test-argocd", "pr-test", false, nil)
+	assert.NoError(t, err)
+
+	_, err = svc.List(context.Background())
+	assert.Error(t, err)
+}
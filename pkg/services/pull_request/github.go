@@ -0,0 +1,98 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const (
+	defaultGithubAPI = "https://api.github.com"
+	githubPageSize   = 100
+)
+
+type githubPullRequest struct {
+	Number int `json:"number"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// GithubService lists the open pull requests of a GitHub repository.
+type GithubService struct {
+	client *http.Client
+	api    string
+	token  string
+	owner  string
+	repo   string
+	labels []string
+}
+
+var _ PullRequestService = (*GithubService)(nil)
+
+// NewGithubService returns a service for the given GitHub repository. An empty api defaults to github.com, an
+// empty token uses anonymous access.
+func NewGithubService(token, api, owner, repo string, labels []string) (PullRequestService, error) {
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("both owner and repo are required for the GitHub pull request generator")
+	}
+	if api == "" {
+		api = defaultGithubAPI
+	}
+	return &GithubService{
+		client: utils.NewHTTPClient(false),
+		api:    strings.TrimSuffix(api, "/"),
+		token:  token,
+		owner:  owner,
+		repo:   repo,
+		labels: labels,
+	}, nil
+}
+
+func (g *GithubService) List(ctx context.Context) ([]*PullRequest, error) {
+	headers := map[string]string{"Accept": "application/vnd.github.v3+json"}
+	if g.token != "" {
+		headers["Authorization"] = "token " + g.token
+	}
+
+	pullRequests := []*PullRequest{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&per_page=%d&page=%d",
+			g.api, url.PathEscape(g.owner), url.PathEscape(g.repo), githubPageSize, page)
+
+		var pulls []githubPullRequest
+		if _, err := utils.GetJSON(ctx, g.client, u, headers, &pulls); err != nil {
+			return nil, fmt.Errorf("error listing pull requests for %s/%s: %v", g.owner, g.repo, err)
+		}
+
+		for _, pull := range pulls {
+			labels := make([]string, 0, len(pull.Labels))
+			for _, label := range pull.Labels {
+				labels = append(labels, label.Name)
+			}
+			if !containLabels(g.labels, labels) {
+				continue
+			}
+			pullRequests = append(pullRequests, &PullRequest{
+				Number:  pull.Number,
+				Branch:  pull.Head.Ref,
+				HeadSHA: pull.Head.SHA,
+				Labels:  labels,
+			})
+		}
+
+		if len(pulls) < githubPageSize {
+			break
+		}
+	}
+
+	return pullRequests, nil
+}
@@ -0,0 +1,83 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func githubMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/argoproj-labs/applicationset/pulls", r.URL.Path)
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "token my-token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			// A full page, so that the service asks for the next one
+			fmt.Fprint(w, "[")
+			for i := 0; i < githubPageSize; i++ {
+				if i > 0 {
+					fmt.Fprint(w, ",")
+				}
+				fmt.Fprintf(w, `{"number": %d, "head": {"ref": "branch-%d", "sha": "sha-%d"}, "labels": []}`, i+1, i+1, i+1)
+			}
+			fmt.Fprint(w, "]")
+		case "2":
+			fmt.Fprint(w, `[{"number": 101, "head": {"ref": "feature", "sha": "abcdef"}, "labels": [{"name": "preview"}]}]`)
+		default:
+			t.Errorf("unexpected page %s", r.URL.Query().Get("page"))
+		}
+	}
+}
+
+func TestGithubList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(githubMockHandler(t)))
+	defer ts.Close()
+
+	svc, err := NewGithubService("my-token", ts.URL, "argoproj-labs", "applicationset", nil)
+	assert.NoError(t, err)
+
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, pullRequests, githubPageSize+1)
+	assert.Equal(t, &PullRequest{Number: 1, Branch: "branch-1", HeadSHA: "sha-1", Labels: []string{}}, pullRequests[0])
+	assert.Equal(t, &PullRequest{Number: 101, Branch: "feature", HeadSHA: "abcdef", Labels: []string{"preview"}}, pullRequests[githubPageSize])
+}
+
+func TestGithubListLabels(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(githubMockHandler(t)))
+	defer ts.Close()
+
+	svc, err := NewGithubService("my-token", ts.URL, "argoproj-labs", "applicationset", []string{"preview"})
+	assert.NoError(t, err)
+
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{{Number: 101, Branch: "feature", HeadSHA: "abcdef", Labels: []string{"preview"}}}, pullRequests)
+}
+
+func TestGithubListError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "Bad credentials"}`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService("", ts.URL, "argoproj-labs", "applicationset", nil)
+	assert.NoError(t, err)
+
+	_, err = svc.List(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Bad credentials")
+}
+
+func TestNewGithubServiceRequiresRepo(t *testing.T) {
+	_, err := NewGithubService("", "", "argoproj-labs", "", nil)
+	assert.Error(t, err)
+}
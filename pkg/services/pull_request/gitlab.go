@@ -0,0 +1,95 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const (
+	defaultGitlabAPI = "https://gitlab.com"
+	gitlabPageSize   = 100
+)
+
+type gitlabMergeRequest struct {
+	IID          int      `json:"iid"`
+	SourceBranch string   `json:"source_branch"`
+	SHA          string   `json:"sha"`
+	Labels       []string `json:"labels"`
+}
+
+// GitLabService lists the open merge requests of a GitLab project.
+type GitLabService struct {
+	client  *http.Client
+	api     string
+	token   string
+	project string
+	labels  []string
+}
+
+var _ PullRequestService = (*GitLabService)(nil)
+
+// NewGitLabService returns a service for the given GitLab project. An empty api defaults to gitlab.com, an
+// empty token uses anonymous access.
+func NewGitLabService(token, api, project string, labels []string) (PullRequestService, error) {
+	if project == "" {
+		return nil, fmt.Errorf("project is required for the GitLab pull request generator")
+	}
+	if api == "" {
+		api = defaultGitlabAPI
+	}
+	return &GitLabService{
+		client:  utils.NewHTTPClient(false),
+		api:     strings.TrimSuffix(api, "/"),
+		token:   token,
+		project: project,
+		labels:  labels,
+	}, nil
+}
+
+func (g *GitLabService) List(ctx context.Context) ([]*PullRequest, error) {
+	headers := map[string]string{}
+	if g.token != "" {
+		headers["PRIVATE-TOKEN"] = g.token
+	}
+
+	query := url.Values{}
+	query.Set("state", "opened")
+	query.Set("per_page", fmt.Sprintf("%d", gitlabPageSize))
+	if len(g.labels) > 0 {
+		query.Set("labels", strings.Join(g.labels, ","))
+	}
+
+	pullRequests := []*PullRequest{}
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		u := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests?%s", g.api, url.PathEscape(g.project), query.Encode())
+
+		var mergeRequests []gitlabMergeRequest
+		respHeaders, err := utils.GetJSON(ctx, g.client, u, headers, &mergeRequests)
+		if err != nil {
+			return nil, fmt.Errorf("error listing merge requests for %s: %v", g.project, err)
+		}
+
+		for _, mergeRequest := range mergeRequests {
+			// The labels are already filtered by the API, this only guards against instances ignoring the filter.
+			if !containLabels(g.labels, mergeRequest.Labels) {
+				continue
+			}
+			pullRequests = append(pullRequests, &PullRequest{
+				Number:  mergeRequest.IID,
+				Branch:  mergeRequest.SourceBranch,
+				HeadSHA: mergeRequest.SHA,
+				Labels:  mergeRequest.Labels,
+			})
+		}
+
+		page = respHeaders.Get("X-Next-Page")
+	}
+
+	return pullRequests, nil
+}
@@ -0,0 +1,58 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The project path must be sent URL-encoded
+		assert.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests", r.URL.EscapedPath())
+		assert.Equal(t, "opened", r.URL.Query().Get("state"))
+		assert.Equal(t, "preview", r.URL.Query().Get("labels"))
+		assert.Equal(t, "my-token", r.Header.Get("PRIVATE-TOKEN"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"iid": 1, "source_branch": "feature-1", "sha": "sha-1", "labels": ["preview"]}]`)
+		case "2":
+			w.Header().Set("X-Next-Page", "")
+			fmt.Fprint(w, `[{"iid": 2, "source_branch": "feature-2", "sha": "sha-2", "labels": ["preview", "other"]}]`)
+		default:
+			t.Errorf("unexpected page %s", r.URL.Query().Get("page"))
+		}
+	}))
+	defer ts.Close()
+
+	svc, err := NewGitLabService("my-token", ts.URL, "group/project", []string{"preview"})
+	assert.NoError(t, err)
+
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{
+		{Number: 1, Branch: "feature-1", HeadSHA: "sha-1", Labels: []string{"preview"}},
+		{Number: 2, Branch: "feature-2", HeadSHA: "sha-2", Labels: []string{"preview", "other"}},
+	}, pullRequests)
+}
+
+func TestGitLabListError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Project Not Found"}`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGitLabService("", ts.URL, "group/project", nil)
+	assert.NoError(t, err)
+
+	_, err = svc.List(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package pull_request
+
+import (
+	"context"
+)
+
+// PullRequest is an open pull request (or merge request) of a repository, as reported by the SCM provider.
+type PullRequest struct {
+	// Number is the number (or merge request IID) of the pull request.
+	Number int
+	// Branch is the name of the source branch of the pull request.
+	Branch string
+	// HeadSHA is the SHA of the commit at the head of the source branch.
+	HeadSHA string
+	// Labels are the labels set on the pull request.
+	Labels []string
+}
+
+// PullRequestService lists the open pull requests of a single repository.
+type PullRequestService interface {
+	// List returns the open pull requests of the repository.
+	List(ctx context.Context) ([]*PullRequest, error)
+}
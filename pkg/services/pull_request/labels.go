@@ -0,0 +1,18 @@
+package pull_request
+
+// containLabels returns true if all the expected labels are part of the labels.
+func containLabels(expectedLabels []string, labels []string) bool {
+	for _, expected := range expectedLabels {
+		found := false
+		for _, label := range labels {
+			if label == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
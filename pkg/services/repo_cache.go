@@ -0,0 +1,247 @@
+package services
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/util/git"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var commitSHARegex = regexp.MustCompile("^[0-9A-Fa-f]{40}$")
+
+// repoCache keeps a local clone of each repository, shared by the reconciles, so that a repository is only fetched
+// when a revision resolves to a commit which isn't checked out yet, instead of once per listing or file read.
+//
+// The git operations on a clone are serialized, e.g. for concurrent reconciles reading different revisions. The unused
+// clones idle for more than maxIdle are deleted whenever a clone is released, then the least recently used unused
+// clones while there are more than maxRepos clones, or while the clones take more than maxSize bytes on disk.
+type repoCache struct {
+	// root is the directory of the clones
+	root     string
+	maxIdle  time.Duration
+	maxRepos int
+	maxSize  int64
+
+	newClient func(repo *v1alpha1.Repository, root string) (git.Client, error)
+	now       func() time.Time
+
+	lock  sync.Mutex
+	repos map[string]*cachedRepo
+	// clones is the number of clones created, which makes their directories unique: a new clone of a repository
+	// never uses the directory of an evicted clone being deleted
+	clones int
+}
+
+// cachedRepo is the local clone of a repository.
+type cachedRepo struct {
+	// lock serializes the git operations on the clone
+	lock sync.Mutex
+	root string
+	// commitSHA is the commit checked out, if any
+	commitSHA string
+
+	// users is the number of operations holding the clone, which is only evicted when it is 0. It is guarded by the
+	// lock of the cache, as lastUsed and size.
+	users    int
+	lastUsed time.Time
+	// size is the size of the clone on disk, in bytes, as measured after its last checkout
+	size int64
+}
+
+func newRepoCache(root string, maxIdle time.Duration, maxRepos int, maxSize int64) *repoCache {
+	return &repoCache{
+		root:     root,
+		maxIdle:  maxIdle,
+		maxRepos: maxRepos,
+		maxSize:  maxSize,
+		newClient: func(repo *v1alpha1.Repository, root string) (git.Client, error) {
+			return git.NewClientExt(repo.Repo, root, repo.GetGitCreds(), repo.IsInsecure(), repo.IsLFSEnabled())
+		},
+		now:   time.Now,
+		repos: map[string]*cachedRepo{},
+	}
+}
+
+// resolveRevision returns the commit SHA of the revision of the repository, e.g. of a branch or a tag.
+func (c *repoCache) resolveRevision(repo *v1alpha1.Repository, revision string) (string, error) {
+	if commitSHARegex.MatchString(revision) {
+		return revision, nil
+	}
+
+	cached := c.acquire(repo.Repo)
+	defer c.release(cached)
+
+	// Listing the remote references doesn't use the clone, so it doesn't wait for the operations on it
+	client, err := c.newClient(repo, cached.root)
+	if err != nil {
+		return "", err
+	}
+	return lsRemote(client, revision)
+}
+
+// readRevision checks out the revision of the repository, fetching it when its commit isn't in the clone yet, and
+// calls read with the client of the checkout. The revision should be resolved already, see resolveRevision, so that
+// successive reads don't resolve it again.
+func (c *repoCache) readRevision(repo *v1alpha1.Repository, revision string, read func(client git.Client) error) error {
+	return c.withClient(repo, func(client git.Client, cached *cachedRepo) error {
+		commitSHA, err := lsRemote(client, revision)
+		if err != nil {
+			return err
+		}
+
+		if cached.commitSHA != commitSHA {
+			cached.commitSHA = ""
+			// The commit may have been fetched already, e.g. for another branch
+			if err := client.Checkout(commitSHA); err != nil {
+				if err := client.Fetch(); err != nil {
+					return errors.Wrap(err, "Error during fetching repo")
+				}
+				if err := client.Checkout(commitSHA); err != nil {
+					return errors.Wrap(err, "Error during repo checkout")
+				}
+			}
+			cached.commitSHA = commitSHA
+		}
+
+		return read(client)
+	})
+}
+
+// lsRemote returns the commit SHA of the revision, which is returned as is when it is a commit SHA already.
+func lsRemote(client git.Client, revision string) (string, error) {
+	if commitSHARegex.MatchString(revision) {
+		return revision, nil
+	}
+	commitSHA, err := client.LsRemote(revision)
+	if err != nil {
+		return "", errors.Wrap(err, "Error during fetching commitSHA")
+	}
+	return commitSHA, nil
+}
+
+// withClient calls f with a client of the clone of the repository, holding the lock of the clone.
+func (c *repoCache) withClient(repo *v1alpha1.Repository, f func(client git.Client, cached *cachedRepo) error) error {
+	cached := c.acquire(repo.Repo)
+	defer c.release(cached)
+
+	cached.lock.Lock()
+	defer cached.lock.Unlock()
+
+	// The client is created for each operation, so that it uses the current credentials of the repository
+	client, err := c.newClient(repo, cached.root)
+	if err != nil {
+		return err
+	}
+	if err := client.Init(); err != nil {
+		return errors.Wrap(err, "Error during initiliazing repo")
+	}
+
+	// The size of the clone only changes when another commit is fetched and checked out
+	commitSHA := cached.commitSHA
+	defer func() {
+		if c.maxSize > 0 && cached.commitSHA != commitSHA {
+			c.setSize(cached, dirSize(cached.root))
+		}
+	}()
+
+	return f(client, cached)
+}
+
+func (c *repoCache) acquire(repoURL string) *cachedRepo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := git.NormalizeGitURL(repoURL)
+	cached, found := c.repos[key]
+	if !found {
+		c.clones++
+		cached = &cachedRepo{root: filepath.Join(c.root, fmt.Sprintf("%x-%d", sha256.Sum256([]byte(key)), c.clones))}
+		c.repos[key] = cached
+	}
+	cached.users++
+	return cached
+}
+
+func (c *repoCache) release(cached *cachedRepo) {
+	c.lock.Lock()
+	cached.users--
+	cached.lastUsed = c.now()
+	evicted := c.evict()
+	c.lock.Unlock()
+
+	// Deleting the clones may take a while, so it doesn't hold the lock of the cache
+	for key, root := range evicted {
+		log.WithField("repo", key).Debug("deleting the local clone of the repository")
+		if err := os.RemoveAll(root); err != nil {
+			log.WithError(err).WithField("repo", key).Warn("unable to delete the local clone of the repository")
+		}
+	}
+}
+
+func (c *repoCache) setSize(cached *cachedRepo, size int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cached.size = size
+}
+
+// evict removes the unused clones idle for more than maxIdle from the cache, then the least recently used unused
+// clones while there are more than maxRepos clones or while they take more than maxSize bytes, and returns the
+// directories of the removed clones by repository. It must be called holding the lock of the cache.
+func (c *repoCache) evict() map[string]string {
+	evicted := map[string]string{}
+	remove := func(key string) {
+		evicted[key] = c.repos[key].root
+		delete(c.repos, key)
+	}
+
+	now := c.now()
+	unused := []string{}
+	var size int64
+	for key, cached := range c.repos {
+		if cached.users == 0 && c.maxIdle > 0 && now.Sub(cached.lastUsed) > c.maxIdle {
+			remove(key)
+			continue
+		}
+		if cached.users == 0 {
+			unused = append(unused, key)
+		}
+		size += cached.size
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return c.repos[unused[i]].lastUsed.Before(c.repos[unused[j]].lastUsed)
+	})
+	for _, key := range unused {
+		tooMany := c.maxRepos > 0 && len(c.repos) > c.maxRepos
+		tooLarge := c.maxSize > 0 && size > c.maxSize
+		if !tooMany && !tooLarge {
+			break
+		}
+		size -= c.repos[key].size
+		remove(key)
+	}
+
+	return evicted
+}
+
+// dirSize returns the size of the files within the directory, ignoring the files that can't be read.
+func dirSize(root string) int64 {
+	var size int64
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
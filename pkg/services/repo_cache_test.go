@@ -0,0 +1,244 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/argo-cd/util/git"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mainCommitSHA    = "0b9e3c4f4e5bb4d5a8ef8e9d6a5c1b2a3d4e5f60"
+	releaseCommitSHA = "1c8f2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"
+)
+
+// fakeRemote is a remote repository, with the commits fetched by its clones.
+type fakeRemote struct {
+	revisions map[string]string
+	fetched   map[string]bool
+	calls     []string
+	// size is the size of the checkouts
+	size int
+}
+
+// fakeGitClient is a client of a clone of a fakeRemote. The methods not used by the cache are left unimplemented.
+type fakeGitClient struct {
+	git.Client
+	remote *fakeRemote
+	root   string
+}
+
+func (f *fakeGitClient) Root() string {
+	return f.root
+}
+
+func (f *fakeGitClient) Init() error {
+	return os.MkdirAll(f.root, 0755)
+}
+
+func (f *fakeGitClient) LsRemote(revision string) (string, error) {
+	f.remote.calls = append(f.remote.calls, "ls-remote "+revision)
+	commitSHA, found := f.remote.revisions[revision]
+	if !found {
+		return "", fmt.Errorf("unknown revision %s", revision)
+	}
+	return commitSHA, nil
+}
+
+func (f *fakeGitClient) Fetch() error {
+	f.remote.calls = append(f.remote.calls, "fetch")
+	for _, commitSHA := range f.remote.revisions {
+		f.remote.fetched[commitSHA] = true
+	}
+	return nil
+}
+
+func (f *fakeGitClient) Checkout(revision string) error {
+	f.remote.calls = append(f.remote.calls, "checkout "+revision)
+	if !f.remote.fetched[revision] {
+		return fmt.Errorf("unknown commit %s", revision)
+	}
+	return ioutil.WriteFile(filepath.Join(f.root, "content"), make([]byte, f.remote.size), 0644)
+}
+
+func newTestRepoCache(t *testing.T, maxIdle time.Duration, maxRepos int, maxSize int64) (*repoCache, map[string]*fakeRemote, *time.Time) {
+	root, err := ioutil.TempDir("", "repo-cache")
+	assert.Nil(t, err)
+
+	remotes := map[string]*fakeRemote{}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cache := newRepoCache(root, maxIdle, maxRepos, maxSize)
+	cache.newClient = func(repo *v1alpha1.Repository, root string) (git.Client, error) {
+		remote, found := remotes[repo.Repo]
+		if !found {
+			remote = &fakeRemote{
+				revisions: map[string]string{"main": mainCommitSHA, "release": releaseCommitSHA},
+				fetched:   map[string]bool{},
+				size:      1024,
+			}
+			remotes[repo.Repo] = remote
+		}
+		return &fakeGitClient{remote: remote, root: root}, nil
+	}
+	cache.now = func() time.Time {
+		return now
+	}
+	return cache, remotes, &now
+}
+
+func TestRepoCacheReadRevision(t *testing.T) {
+	cache, remotes, _ := newTestRepoCache(t, 0, 0, 0)
+	defer os.RemoveAll(cache.root)
+	repo := &v1alpha1.Repository{Repo: "https://github.com/argoproj/argocd-example-apps"}
+
+	commitSHA, err := cache.resolveRevision(repo, "main")
+	assert.Nil(t, err)
+	assert.Equal(t, mainCommitSHA, commitSHA)
+
+	// The commit is only fetched and checked out by the first read
+	roots := []string{}
+	for i := 0; i < 3; i++ {
+		err = cache.readRevision(repo, commitSHA, func(client git.Client) error {
+			roots = append(roots, client.Root())
+			return nil
+		})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []string{roots[0], roots[0], roots[0]}, roots)
+	assert.Equal(t, []string{
+		"ls-remote main",
+		"checkout " + mainCommitSHA,
+		"fetch",
+		"checkout " + mainCommitSHA,
+	}, remotes[repo.Repo].calls)
+
+	// Both commits were fetched already
+	remotes[repo.Repo].calls = nil
+	err = cache.readRevision(repo, "release", func(client git.Client) error {
+		return nil
+	})
+	assert.Nil(t, err)
+	err = cache.readRevision(repo, mainCommitSHA, func(client git.Client) error {
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ls-remote release",
+		"checkout " + releaseCommitSHA,
+		"checkout " + mainCommitSHA,
+	}, remotes[repo.Repo].calls)
+
+	// The clones are shared by the URLs of the same repository
+	err = cache.readRevision(&v1alpha1.Repository{Repo: "https://github.com/argoproj/argocd-example-apps.git"}, mainCommitSHA, func(client git.Client) error {
+		assert.Equal(t, roots[0], client.Root())
+		return nil
+	})
+	assert.Nil(t, err)
+
+	_, err = cache.resolveRevision(repo, "unknown")
+	assert.EqualError(t, err, "Error during fetching commitSHA: unknown revision unknown")
+
+	err = cache.readRevision(repo, mainCommitSHA, func(client git.Client) error {
+		return fmt.Errorf("read error")
+	})
+	assert.EqualError(t, err, "read error")
+}
+
+func TestRepoCacheEvict(t *testing.T) {
+	cache, _, now := newTestRepoCache(t, time.Hour, 2, 0)
+	defer os.RemoveAll(cache.root)
+
+	read := func(repoURL string) string {
+		var root string
+		err := cache.readRevision(&v1alpha1.Repository{Repo: repoURL}, mainCommitSHA, func(client git.Client) error {
+			root = client.Root()
+			return nil
+		})
+		assert.Nil(t, err)
+		return root
+	}
+	exists := func(root string) bool {
+		_, err := os.Stat(root)
+		return err == nil
+	}
+
+	first := read("https://github.com/argoproj/first")
+	*now = now.Add(time.Minute)
+	second := read("https://github.com/argoproj/second")
+	*now = now.Add(time.Minute)
+	assert.True(t, exists(first))
+	assert.True(t, exists(second))
+	assert.Equal(t, cache.root, filepath.Dir(first))
+
+	// The least recently used clone is evicted beyond maxRepos
+	read("https://github.com/argoproj/first")
+	*now = now.Add(time.Minute)
+	third := read("https://github.com/argoproj/third")
+	assert.True(t, exists(first))
+	assert.False(t, exists(second))
+	assert.True(t, exists(third))
+
+	// The clones idle for more than maxIdle are evicted
+	*now = now.Add(time.Hour)
+	read("https://github.com/argoproj/third")
+	assert.False(t, exists(first))
+	assert.True(t, exists(third))
+	assert.Len(t, cache.repos, 1)
+
+	// The clones in use are not evicted
+	cached := cache.acquire("https://github.com/argoproj/third")
+	*now = now.Add(2 * time.Hour)
+	read("https://github.com/argoproj/fourth")
+	assert.True(t, exists(third))
+	cache.release(cached)
+	assert.Len(t, cache.repos, 2)
+}
+
+func TestRepoCacheEvictSize(t *testing.T) {
+	cache, remotes, now := newTestRepoCache(t, 0, 0, 2500)
+	defer os.RemoveAll(cache.root)
+
+	read := func(repoURL string) string {
+		var root string
+		err := cache.readRevision(&v1alpha1.Repository{Repo: repoURL}, mainCommitSHA, func(client git.Client) error {
+			root = client.Root()
+			return nil
+		})
+		assert.Nil(t, err)
+		*now = now.Add(time.Minute)
+		return root
+	}
+	exists := func(root string) bool {
+		_, err := os.Stat(root)
+		return err == nil
+	}
+
+	// The least recently used clones are evicted while the clones take more than maxSize
+	first := read("https://github.com/argoproj/first")
+	second := read("https://github.com/argoproj/second")
+	assert.Equal(t, int64(1024), cache.repos[git.NormalizeGitURL("https://github.com/argoproj/first")].size)
+	third := read("https://github.com/argoproj/third")
+	assert.False(t, exists(first))
+	assert.True(t, exists(second))
+	assert.True(t, exists(third))
+
+	// A clone growing beyond maxSize evicts the others
+	remotes["https://github.com/argoproj/third"].size = 2048
+	err := cache.readRevision(&v1alpha1.Repository{Repo: "https://github.com/argoproj/third"}, "release", func(client git.Client) error {
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.False(t, exists(second))
+	assert.True(t, exists(third))
+	assert.Len(t, cache.repos, 1)
+
+	// A new clone of an evicted repository doesn't reuse the directory of the evicted clone
+	assert.NotEqual(t, first, read("https://github.com/argoproj/first"))
+}
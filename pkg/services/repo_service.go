@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/reposerver/apiclient"
@@ -25,23 +28,49 @@ type RepositoryDB interface {
 type argoCDService struct {
 	repositoriesDB RepositoryDB
 	repoClientset  apiclient.Clientset
+	repoCache      *repoCache
 }
 
 type Repos interface {
+	// ResolveRevision returns the commit SHA of the revision of the repository, e.g. of a branch, to read the same
+	// commit across the following calls
+	ResolveRevision(ctx context.Context, repoURL string, revision string) (string, error)
 	GetApps(ctx context.Context, repoURL string, revision string) ([]string, error)
+	// GetDirectories returns every directory of the repository at the revision, whether or not it is an application
+	GetDirectories(ctx context.Context, repoURL string, revision string) ([]string, error)
 	GetPaths(ctx context.Context, repoURL string, revision string, pattern string) ([]string, error)
 	GetFileContent(ctx context.Context, repoURL string, revision string, path string) ([]byte, error)
 }
 
-func NewArgoCDService(ctx context.Context, clientset kubernetes.Interface, namespace string, repoServerAddress string) Repos {
+// NewArgoCDService returns a Repos listing the applications with the Argo CD repo server, and reading the other
+// listings and the files from local clones of the repositories. The unused clones are deleted after repoCacheMaxIdle,
+// or once there are more than repoCacheMaxRepos clones or the clones take more than repoCacheMaxSize bytes on disk,
+// the least recently used first.
+func NewArgoCDService(ctx context.Context, clientset kubernetes.Interface, namespace string, repoServerAddress string, repoCacheMaxIdle time.Duration, repoCacheMaxRepos int, repoCacheMaxSize int64) Repos {
 	settingsMgr := settings.NewSettingsManager(ctx, clientset, namespace)
 
+	// The clones left by a previous run are unknown to the cache, which would never delete them
+	repoCacheRoot := filepath.Join(os.TempDir(), "applicationset-repos")
+	if err := os.RemoveAll(repoCacheRoot); err != nil {
+		log.WithError(err).Warn("unable to delete the local clones of a previous run")
+	}
+
 	return &argoCDService{
 		repositoriesDB: db.NewDB(namespace, settingsMgr, clientset).(RepositoryDB),
 		repoClientset:  apiclient.NewRepoServerClientset(repoServerAddress, 5),
+		repoCache:      newRepoCache(repoCacheRoot, repoCacheMaxIdle, repoCacheMaxRepos, repoCacheMaxSize),
 	}
 }
 
+func (a *argoCDService) ResolveRevision(ctx context.Context, repoURL string, revision string) (string, error) {
+	repo, err := a.repositoriesDB.GetRepository(ctx, repoURL)
+	if err != nil {
+		return "", errors.Wrap(err, "Error in GetRepository")
+	}
+
+	return a.repoCache.resolveRevision(repo, revision)
+}
+
 func (a *argoCDService) GetApps(ctx context.Context, repoURL string, revision string) ([]string, error) {
 	repo, err := a.repositoriesDB.GetRepository(ctx, repoURL)
 	if err != nil {
@@ -73,26 +102,43 @@ func (a *argoCDService) GetApps(ctx context.Context, repoURL string, revision st
 	return res, nil
 }
 
-func (a *argoCDService) GetPaths(ctx context.Context, repoURL string, revision string, pattern string) ([]string, error) {
+func (a *argoCDService) GetDirectories(ctx context.Context, repoURL string, revision string) ([]string, error) {
 	repo, err := a.repositoriesDB.GetRepository(ctx, repoURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error in GetRepository")
 	}
 
-	gitRepoClient, err := git.NewClient(repo.Repo, repo.GetGitCreds(), repo.IsInsecure(), repo.IsLFSEnabled())
-
+	var dirs []string
+	err = a.repoCache.readRevision(repo, revision, func(gitRepoClient git.Client) error {
+		dirs, err = listDirectories(gitRepoClient.Root())
+		if err != nil {
+			return errors.Wrap(err, "Error during listing directories of local repo")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = checkoutRepo(gitRepoClient, revision)
+	return dirs, nil
+}
+
+func (a *argoCDService) GetPaths(ctx context.Context, repoURL string, revision string, pattern string) ([]string, error) {
+	repo, err := a.repositoriesDB.GetRepository(ctx, repoURL)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "Error in GetRepository")
 	}
 
-	paths, err := gitRepoClient.LsFiles(pattern)
+	var paths []string
+	err = a.repoCache.readRevision(repo, revision, func(gitRepoClient git.Client) error {
+		paths, err = gitRepoClient.LsFiles(pattern)
+		if err != nil {
+			return errors.Wrap(err, "Error during listing files of local repo")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "Error during listing files of local repo")
+		return nil, err
 	}
 
 	return paths, nil
@@ -104,18 +150,11 @@ func (a *argoCDService) GetFileContent(ctx context.Context, repoURL string, revi
 		return nil, errors.Wrap(err, "Error in GetRepository")
 	}
 
-	gitRepoClient, err := git.NewClient(repo.Repo, repo.GetGitCreds(), repo.IsInsecure(), repo.IsLFSEnabled())
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = checkoutRepo(gitRepoClient, revision)
-	if err != nil {
-		return nil, err
-	}
-
-	bytes, err := ioutil.ReadFile(filepath.Join(gitRepoClient.Root(), path))
+	var bytes []byte
+	err = a.repoCache.readRevision(repo, revision, func(gitRepoClient git.Client) error {
+		bytes, err = ioutil.ReadFile(filepath.Join(gitRepoClient.Root(), path))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -123,24 +162,29 @@ func (a *argoCDService) GetFileContent(ctx context.Context, repoURL string, revi
 	return bytes, nil
 }
 
-func checkoutRepo(gitRepoClient git.Client, revision string) error {
-	err := gitRepoClient.Init()
-	if err != nil {
-		return errors.Wrap(err, "Error during initiliazing repo")
-	}
-
-	err = gitRepoClient.Fetch()
-	if err != nil {
-		return errors.Wrap(err, "Error during fetching repo")
-	}
-
-	commitSHA, err := gitRepoClient.LsRemote(revision)
-	if err != nil {
-		return errors.Wrap(err, "Error during fetching commitSHA")
-	}
-	err = gitRepoClient.Checkout(commitSHA)
+// listDirectories returns the slash separated paths of the directories below root, skipping the hidden directories,
+// e.g. .git, and their content.
+func listDirectories(root string) ([]string, error) {
+	res := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == root {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		dir, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		res = append(res, filepath.ToSlash(dir))
+		return nil
+	})
 	if err != nil {
-		return errors.Wrap(err, "Error during repo checkout")
+		return nil, err
 	}
-	return nil
+	return res, nil
 }
@@ -0,0 +1,15 @@
+package scm_provider
+
+import "fmt"
+
+// cloneURL picks the clone URL matching the requested protocol, https being the default.
+func cloneURL(cloneProtocol, httpsURL, sshURL string) (string, error) {
+	switch cloneProtocol {
+	case "", "https":
+		return httpsURL, nil
+	case "ssh":
+		return sshURL, nil
+	default:
+		return "", fmt.Errorf("unknown clone protocol %q, expected https or ssh", cloneProtocol)
+	}
+}
@@ -0,0 +1,17 @@
+package scm_provider
+
+import (
+	"context"
+)
+
+// FakeProvider is a SCMProviderService returning a fixed list of repositories, for use in tests.
+type FakeProvider struct {
+	Repos []*Repository
+	Err   error
+}
+
+var _ SCMProviderService = (*FakeProvider)(nil)
+
+func (m *FakeProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+	return m.Repos, m.Err
+}
@@ -0,0 +1,143 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const giteaPageSize = 50
+
+type giteaRepository struct {
+	Name          string `json:"name"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch"`
+	Empty         bool   `json:"empty"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type giteaBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// GiteaProvider discovers the repositories of a Gitea organization.
+type GiteaProvider struct {
+	client      *http.Client
+	api         string
+	token       string
+	owner       string
+	allBranches bool
+}
+
+var _ SCMProviderService = (*GiteaProvider)(nil)
+
+// NewGiteaProvider returns a provider for the given Gitea organization. An empty token uses anonymous access.
+func NewGiteaProvider(token, api, owner string, insecure, allBranches bool) (*GiteaProvider, error) {
+	if api == "" {
+		return nil, fmt.Errorf("api is required for the Gitea SCM provider")
+	}
+	if owner == "" {
+		return nil, fmt.Errorf("owner is required for the Gitea SCM provider")
+	}
+	return &GiteaProvider{
+		client:      utils.NewHTTPClient(insecure),
+		api:         strings.TrimSuffix(api, "/"),
+		token:       token,
+		owner:       owner,
+		allBranches: allBranches,
+	}, nil
+}
+
+func (g *GiteaProvider) headers() map[string]string {
+	headers := map[string]string{}
+	if g.token != "" {
+		headers["Authorization"] = "token " + g.token
+	}
+	return headers
+}
+
+func (g *GiteaProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+	repos := []*Repository{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/api/v1/orgs/%s/repos?limit=%d&page=%d", g.api, url.PathEscape(g.owner), giteaPageSize, page)
+
+		var giteaRepos []giteaRepository
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &giteaRepos); err != nil {
+			return nil, fmt.Errorf("error listing repositories for %s: %v", g.owner, err)
+		}
+
+		for _, giteaRepo := range giteaRepos {
+			// Empty repositories don't have any branch
+			if giteaRepo.Empty {
+				continue
+			}
+
+			repoURL, err := cloneURL(cloneProtocol, giteaRepo.CloneURL, giteaRepo.SSHURL)
+			if err != nil {
+				return nil, err
+			}
+
+			branches, err := g.branches(ctx, giteaRepo)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, branch := range branches {
+				repos = append(repos, &Repository{
+					Organization: giteaRepo.Owner.Login,
+					Repository:   giteaRepo.Name,
+					URL:          repoURL,
+					Branch:       branch.Name,
+					SHA:          branch.Commit.ID,
+				})
+			}
+		}
+
+		if len(giteaRepos) < giteaPageSize {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// branches returns either the default branch of the repository, or all of its branches.
+func (g *GiteaProvider) branches(ctx context.Context, repo giteaRepository) ([]giteaBranch, error) {
+	repoPath := fmt.Sprintf("%s/api/v1/repos/%s/%s", g.api, url.PathEscape(repo.Owner.Login), url.PathEscape(repo.Name))
+
+	if !g.allBranches {
+		var branch giteaBranch
+		u := fmt.Sprintf("%s/branches/%s", repoPath, url.PathEscape(repo.DefaultBranch))
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &branch); err != nil {
+			return nil, fmt.Errorf("error getting default branch of %s/%s: %v", repo.Owner.Login, repo.Name, err)
+		}
+		return []giteaBranch{branch}, nil
+	}
+
+	branches := []giteaBranch{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/branches?limit=%d&page=%d", repoPath, giteaPageSize, page)
+
+		var pageBranches []giteaBranch
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &pageBranches); err != nil {
+			return nil, fmt.Errorf("error listing branches of %s/%s: %v", repo.Owner.Login, repo.Name, err)
+		}
+		branches = append(branches, pageBranches...)
+
+		if len(pageBranches) < giteaPageSize {
+			break
+		}
+	}
+
+	return branches, nil
+}
@@ -0,0 +1,42 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteaListRepos(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v1/orgs/test-argocd/repos":
+			fmt.Fprint(w, `[
+				{"name": "pr-test", "clone_url": "https://gitea.com/test-argocd/pr-test.git", "ssh_url": "git@gitea.com:test-argocd/pr-test.git", "default_branch": "main", "empty": false, "owner": {"login": "test-argocd"}},
+				{"name": "empty", "clone_url": "https://gitea.com/test-argocd/empty.git", "ssh_url": "git@gitea.com:test-argocd/empty.git", "default_branch": "main", "empty": true, "owner": {"login": "test-argocd"}}
+			]`)
+		case "/api/v1/repos/test-argocd/pr-test/branches":
+			fmt.Fprint(w, `[{"name": "main", "commit": {"id": "72687815ccba81ef014a96201cc2e846a68789d8"}}, {"name": "test", "commit": {"id": "7bbaf62d92ddfafd9cc8b340c619abaec32bc09f"}}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	// The test server uses a self-signed certificate
+	provider, err := NewGiteaProvider("", ts.URL, "test-argocd", true, true)
+	assert.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "ssh")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Repository{
+		{Organization: "test-argocd", Repository: "pr-test", URL: "git@gitea.com:test-argocd/pr-test.git", Branch: "main", SHA: "72687815ccba81ef014a96201cc2e846a68789d8"},
+		{Organization: "test-argocd", Repository: "pr-test", URL: "git@gitea.com:test-argocd/pr-test.git", Branch: "test", SHA: "7bbaf62d92ddfafd9cc8b340c619abaec32bc09f"},
+	}, repos)
+}
@@ -0,0 +1,141 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const (
+	defaultGithubAPI = "https://api.github.com"
+	githubPageSize   = 100
+)
+
+type githubRepository struct {
+	Name          string `json:"name"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type githubBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// GithubProvider discovers the repositories of a GitHub organization.
+type GithubProvider struct {
+	client       *http.Client
+	api          string
+	token        string
+	organization string
+	allBranches  bool
+}
+
+var _ SCMProviderService = (*GithubProvider)(nil)
+
+// NewGithubProvider returns a provider for the given GitHub organization. An empty api defaults to github.com,
+// an empty token uses anonymous access.
+func NewGithubProvider(token, api, organization string, allBranches bool) (*GithubProvider, error) {
+	if organization == "" {
+		return nil, fmt.Errorf("organization is required for the GitHub SCM provider")
+	}
+	if api == "" {
+		api = defaultGithubAPI
+	}
+	return &GithubProvider{
+		client:       utils.NewHTTPClient(false),
+		api:          strings.TrimSuffix(api, "/"),
+		token:        token,
+		organization: organization,
+		allBranches:  allBranches,
+	}, nil
+}
+
+func (g *GithubProvider) headers() map[string]string {
+	headers := map[string]string{"Accept": "application/vnd.github.v3+json"}
+	if g.token != "" {
+		headers["Authorization"] = "token " + g.token
+	}
+	return headers
+}
+
+func (g *GithubProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+	repos := []*Repository{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/orgs/%s/repos?per_page=%d&page=%d", g.api, url.PathEscape(g.organization), githubPageSize, page)
+
+		var githubRepos []githubRepository
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &githubRepos); err != nil {
+			return nil, fmt.Errorf("error listing repositories for %s: %v", g.organization, err)
+		}
+
+		for _, githubRepo := range githubRepos {
+			repoURL, err := cloneURL(cloneProtocol, githubRepo.CloneURL, githubRepo.SSHURL)
+			if err != nil {
+				return nil, err
+			}
+
+			branches, err := g.branches(ctx, githubRepo)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, branch := range branches {
+				repos = append(repos, &Repository{
+					Organization: githubRepo.Owner.Login,
+					Repository:   githubRepo.Name,
+					URL:          repoURL,
+					Branch:       branch.Name,
+					SHA:          branch.Commit.SHA,
+				})
+			}
+		}
+
+		if len(githubRepos) < githubPageSize {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// branches returns either the default branch of the repository, or all of its branches.
+func (g *GithubProvider) branches(ctx context.Context, repo githubRepository) ([]githubBranch, error) {
+	repoPath := fmt.Sprintf("%s/repos/%s/%s", g.api, url.PathEscape(repo.Owner.Login), url.PathEscape(repo.Name))
+
+	if !g.allBranches {
+		var branch githubBranch
+		u := fmt.Sprintf("%s/branches/%s", repoPath, url.PathEscape(repo.DefaultBranch))
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &branch); err != nil {
+			return nil, fmt.Errorf("error getting default branch of %s/%s: %v", repo.Owner.Login, repo.Name, err)
+		}
+		return []githubBranch{branch}, nil
+	}
+
+	branches := []githubBranch{}
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/branches?per_page=%d&page=%d", repoPath, githubPageSize, page)
+
+		var pageBranches []githubBranch
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &pageBranches); err != nil {
+			return nil, fmt.Errorf("error listing branches of %s/%s: %v", repo.Owner.Login, repo.Name, err)
+		}
+		branches = append(branches, pageBranches...)
+
+		if len(pageBranches) < githubPageSize {
+			break
+		}
+	}
+
+	return branches, nil
+}
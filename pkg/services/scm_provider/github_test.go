@@ -0,0 +1,80 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func githubMockHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token my-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/orgs/argoproj/repos":
+			fmt.Fprint(w, `[{"name": "argo-cd", "clone_url": "https://github.com/argoproj/argo-cd.git", "ssh_url": "git@github.com:argoproj/argo-cd.git", "default_branch": "master", "owner": {"login": "argoproj"}}]`)
+		case "/repos/argoproj/argo-cd/branches/master":
+			fmt.Fprint(w, `{"name": "master", "commit": {"sha": "1111"}}`)
+		case "/repos/argoproj/argo-cd/branches":
+			fmt.Fprint(w, `[{"name": "master", "commit": {"sha": "1111"}}, {"name": "release-1.8", "commit": {"sha": "2222"}}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGithubListRepos(t *testing.T) {
+	ts := httptest.NewServer(githubMockHandler(t))
+	defer ts.Close()
+
+	cases := []struct {
+		name          string
+		allBranches   bool
+		cloneProtocol string
+		expected      []*Repository
+		expectedErr   bool
+	}{
+		{
+			name: "default branch over https",
+			expected: []*Repository{
+				{Organization: "argoproj", Repository: "argo-cd", URL: "https://github.com/argoproj/argo-cd.git", Branch: "master", SHA: "1111"},
+			},
+		},
+		{
+			name:          "all branches over ssh",
+			allBranches:   true,
+			cloneProtocol: "ssh",
+			expected: []*Repository{
+				{Organization: "argoproj", Repository: "argo-cd", URL: "git@github.com:argoproj/argo-cd.git", Branch: "master", SHA: "1111"},
+				{Organization: "argoproj", Repository: "argo-cd", URL: "git@github.com:argoproj/argo-cd.git", Branch: "release-1.8", SHA: "2222"},
+			},
+		},
+		{
+			name:          "unknown clone protocol",
+			cloneProtocol: "ftp",
+			expectedErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		cc := c
+		t.Run(cc.name, func(t *testing.T) {
+			provider, err := NewGithubProvider("my-token", ts.URL, "argoproj", cc.allBranches)
+			assert.NoError(t, err)
+
+			repos, err := provider.ListRepos(context.Background(), cc.cloneProtocol)
+			if cc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, cc.expected, repos)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const (
+	defaultGitlabAPI = "https://gitlab.com"
+	gitlabPageSize   = 100
+)
+
+type gitlabProject struct {
+	ID            int    `json:"id"`
+	Path          string `json:"path"`
+	HTTPURL       string `json:"http_url_to_repo"`
+	SSHURL        string `json:"ssh_url_to_repo"`
+	DefaultBranch string `json:"default_branch"`
+	Namespace     struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+}
+
+type gitlabBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// GitlabProvider discovers the repositories (projects) of a GitLab group.
+type GitlabProvider struct {
+	client           *http.Client
+	api              string
+	token            string
+	group            string
+	includeSubgroups bool
+	allBranches      bool
+}
+
+var _ SCMProviderService = (*GitlabProvider)(nil)
+
+// NewGitlabProvider returns a provider for the given GitLab group. An empty api defaults to gitlab.com, an empty
+// token uses anonymous access.
+func NewGitlabProvider(token, api, group string, includeSubgroups, allBranches bool) (*GitlabProvider, error) {
+	if group == "" {
+		return nil, fmt.Errorf("group is required for the GitLab SCM provider")
+	}
+	if api == "" {
+		api = defaultGitlabAPI
+	}
+	return &GitlabProvider{
+		client:           utils.NewHTTPClient(false),
+		api:              strings.TrimSuffix(api, "/"),
+		token:            token,
+		group:            group,
+		includeSubgroups: includeSubgroups,
+		allBranches:      allBranches,
+	}, nil
+}
+
+func (g *GitlabProvider) headers() map[string]string {
+	headers := map[string]string{}
+	if g.token != "" {
+		headers["PRIVATE-TOKEN"] = g.token
+	}
+	return headers
+}
+
+func (g *GitlabProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+	query := url.Values{}
+	query.Set("include_subgroups", strconv.FormatBool(g.includeSubgroups))
+	query.Set("per_page", strconv.Itoa(gitlabPageSize))
+
+	repos := []*Repository{}
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		u := fmt.Sprintf("%s/api/v4/groups/%s/projects?%s", g.api, url.PathEscape(g.group), query.Encode())
+
+		var projects []gitlabProject
+		respHeaders, err := utils.GetJSON(ctx, g.client, u, g.headers(), &projects)
+		if err != nil {
+			return nil, fmt.Errorf("error listing projects for %s: %v", g.group, err)
+		}
+
+		for _, project := range projects {
+			repoURL, err := cloneURL(cloneProtocol, project.HTTPURL, project.SSHURL)
+			if err != nil {
+				return nil, err
+			}
+
+			branches, err := g.branches(ctx, project)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, branch := range branches {
+				repos = append(repos, &Repository{
+					Organization: project.Namespace.FullPath,
+					Repository:   project.Path,
+					URL:          repoURL,
+					Branch:       branch.Name,
+					SHA:          branch.Commit.ID,
+				})
+			}
+		}
+
+		page = respHeaders.Get("X-Next-Page")
+	}
+
+	return repos, nil
+}
+
+// branches returns either the default branch of the project, or all of its branches.
+func (g *GitlabProvider) branches(ctx context.Context, project gitlabProject) ([]gitlabBranch, error) {
+	branchesPath := fmt.Sprintf("%s/api/v4/projects/%d/repository/branches", g.api, project.ID)
+
+	if !g.allBranches {
+		// Empty projects don't have a default branch
+		if project.DefaultBranch == "" {
+			return nil, nil
+		}
+		var branch gitlabBranch
+		u := fmt.Sprintf("%s/%s", branchesPath, url.PathEscape(project.DefaultBranch))
+		if _, err := utils.GetJSON(ctx, g.client, u, g.headers(), &branch); err != nil {
+			return nil, fmt.Errorf("error getting default branch of %s/%s: %v", project.Namespace.FullPath, project.Path, err)
+		}
+		return []gitlabBranch{branch}, nil
+	}
+
+	branches := []gitlabBranch{}
+	for page := "1"; page != ""; {
+		u := fmt.Sprintf("%s?per_page=%d&page=%s", branchesPath, gitlabPageSize, page)
+
+		var pageBranches []gitlabBranch
+		respHeaders, err := utils.GetJSON(ctx, g.client, u, g.headers(), &pageBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error listing branches of %s/%s: %v", project.Namespace.FullPath, project.Path, err)
+		}
+		branches = append(branches, pageBranches...)
+
+		page = respHeaders.Get("X-Next-Page")
+	}
+
+	return branches, nil
+}
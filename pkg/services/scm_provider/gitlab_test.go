@@ -0,0 +1,58 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitlabListRepos(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "my-token", r.Header.Get("PRIVATE-TOKEN"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.EscapedPath() {
+		case "/api/v4/groups/my-group%2Fsub/projects":
+			assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[{"id": 1, "path": "service-a", "http_url_to_repo": "https://gitlab.com/my-group/sub/service-a.git", "ssh_url_to_repo": "git@gitlab.com:my-group/sub/service-a.git", "default_branch": "main", "namespace": {"full_path": "my-group/sub"}}]`)
+			} else {
+				fmt.Fprint(w, `[{"id": 2, "path": "empty", "http_url_to_repo": "https://gitlab.com/my-group/sub/empty.git", "ssh_url_to_repo": "git@gitlab.com:my-group/sub/empty.git", "default_branch": "", "namespace": {"full_path": "my-group/sub"}}]`)
+			}
+		case "/api/v4/projects/1/repository/branches/main":
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "aaaa"}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	provider, err := NewGitlabProvider("my-token", ts.URL, "my-group/sub", true, false)
+	assert.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Repository{
+		{Organization: "my-group/sub", Repository: "service-a", URL: "https://gitlab.com/my-group/sub/service-a.git", Branch: "main", SHA: "aaaa"},
+	}, repos)
+}
+
+func TestGitlabListReposError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Group Not Found"}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewGitlabProvider("", ts.URL, "my-group", false, false)
+	assert.NoError(t, err)
+
+	_, err = provider.ListRepos(context.Background(), "")
+	assert.Error(t, err)
+}
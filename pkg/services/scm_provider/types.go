@@ -0,0 +1,26 @@
+package scm_provider
+
+import (
+	"context"
+)
+
+// Repository is a branch of a repository discovered in an organization.
+type Repository struct {
+	// Organization is the organization (or group, or owner) the repository belongs to.
+	Organization string
+	// Repository is the name of the repository.
+	Repository string
+	// URL is the clone URL of the repository, using the requested clone protocol.
+	URL string
+	// Branch is the name of the discovered branch.
+	Branch string
+	// SHA is the SHA of the commit at the head of the branch.
+	SHA string
+}
+
+// SCMProviderService discovers the repositories of an organization.
+type SCMProviderService interface {
+	// ListRepos returns the repositories of the organization. Depending on the configuration of the service,
+	// either the default branch of every repository or every branch is returned, one Repository per branch.
+	ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error)
+}
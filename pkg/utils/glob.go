@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"path"
+	"strings"
+)
+
+// GlobMatch reports whether the slash separated name matches the pattern. The pattern has the syntax of path.Match,
+// and a "**" segment matches zero or more segments, e.g. "apps/**/config.json" matches "apps/config.json" and
+// "apps/prod/eu/config.json".
+func GlobMatch(pattern string, name string) (bool, error) {
+	// Validate the whole pattern up front, since the matching stops at the first segment that doesn't match
+	if _, err := path.Match(pattern, ""); err != nil {
+		return false, err
+	}
+	return globMatchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func globMatchSegments(patterns []string, names []string) (bool, error) {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// Consecutive "**" segments are equivalent to a single one
+			for len(patterns) > 0 && patterns[0] == "**" {
+				patterns = patterns[1:]
+			}
+			if len(patterns) == 0 {
+				return true, nil
+			}
+			for i := range names {
+				match, err := globMatchSegments(patterns, names[i:])
+				if err != nil || match {
+					return match, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(names) == 0 {
+			return false, nil
+		}
+		match, err := path.Match(patterns[0], names[0])
+		if err != nil || !match {
+			return false, err
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0, nil
+}
+
+// GitPathspec returns a git pathspec, as used by git ls-files, matching at least the names matched by the GlobMatch
+// pattern: the wildcards of a pathspec also match slashes, so "**" is turned into "*".
+func GitPathspec(pattern string) string {
+	res := strings.ReplaceAll(pattern, "**/", "*")
+	res = strings.ReplaceAll(res, "/**", "*")
+	return strings.ReplaceAll(res, "**", "*")
+}
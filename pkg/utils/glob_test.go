@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern       string
+		name          string
+		expected      bool
+		expectedError string
+	}{
+		{pattern: "apps/*", name: "apps/guestbook", expected: true},
+		{pattern: "apps/*", name: "apps/guestbook/overlays", expected: false},
+		{pattern: "apps/*", name: "apps", expected: false},
+		{pattern: "apps/**", name: "apps", expected: true},
+		{pattern: "apps/**", name: "apps/guestbook/overlays", expected: true},
+		{pattern: "**", name: "apps/guestbook", expected: true},
+		{pattern: "**/config.json", name: "config.json", expected: true},
+		{pattern: "**/config.json", name: "clusters/prod/eu-west/config.json", expected: true},
+		{pattern: "**/config.json", name: "clusters/prod/eu-west/config.yaml", expected: false},
+		{pattern: "clusters/**/config.json", name: "clusters/config.json", expected: true},
+		{pattern: "clusters/**/config.json", name: "clusters/prod/eu-west/config.json", expected: true},
+		{pattern: "clusters/**/config.json", name: "other/prod/config.json", expected: false},
+		{pattern: "clusters/**/**/config.json", name: "clusters/config.json", expected: true},
+		{pattern: "clusters/**/prod/*.json", name: "clusters/eu/prod/config.json", expected: true},
+		{pattern: "clusters/**/prod/*.json", name: "clusters/eu/prod/sub/config.json", expected: false},
+		{pattern: "clusters/*/config.json", name: "clusters/prod/eu-west/config.json", expected: false},
+		{pattern: "clusters/[", name: "clusters/prod", expectedError: "syntax error in pattern"},
+	} {
+		t.Run(c.pattern+" "+c.name, func(t *testing.T) {
+			got, err := GlobMatch(c.pattern, c.name)
+			if c.expectedError != "" {
+				assert.EqualError(t, err, c.expectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, c.expected, got)
+		})
+	}
+}
+
+func TestGitPathspec(t *testing.T) {
+	assert.Equal(t, "clusters/*/config.json", GitPathspec("clusters/*/config.json"))
+	assert.Equal(t, "*config.json", GitPathspec("**/config.json"))
+	assert.Equal(t, "clusters/*config.json", GitPathspec("clusters/**/config.json"))
+	assert.Equal(t, "apps*", GitPathspec("apps/**"))
+	assert.Equal(t, "*", GitPathspec("**"))
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// renderGoTemplate renders every string of the JSON marshalled template, map keys included, with text/template.
+// Rendering the strings one by one, rather than the JSON document as a whole, ensures that rendered values never
+// need to be escaped and can't break the structure of the template.
+func (r *Render) renderGoTemplate(tmplBytes []byte, params map[string]interface{}) ([]byte, error) {
+	var tmpl interface{}
+	if err := json.Unmarshal(tmplBytes, &tmpl); err != nil {
+		return nil, err
+	}
+
+	data := goTemplateData(params)
+
+	rendered, err := renderGoTemplateValue(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rendered)
+}
+
+func renderGoTemplateValue(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderGoTemplateString(v, data)
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedKey, err := renderGoTemplateString(key, data)
+			if err != nil {
+				return nil, err
+			}
+			renderedItem, err := renderGoTemplateValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			res[renderedKey] = renderedItem
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			renderedItem, err := renderGoTemplateValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = renderedItem
+		}
+		return res, nil
+	default:
+		return value, nil
+	}
+}
+
+func renderGoTemplateString(text string, data map[string]interface{}) (string, error) {
+	// Most strings of a template are plain values, don't pay for parsing them
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").Funcs(templateFunctions).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %v", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %q: %v", text, err)
+	}
+
+	return buf.String(), nil
+}
+
+// goTemplateData returns the parameters as template data. Parameters keep their types, so nested parameters are
+// available with the usual Go template syntax ({{ .values.foo }}, {{ range .items }}). Generators of string
+// parameters produce flat names, where dots denote nesting (e.g. "values.foo"): such a parameter is available under
+// its flat name, for use with the index function ({{ index . "path.basename" }}), and is also nested under its
+// dotted name ({{ .values.foo }}) unless that conflicts with another parameter.
+func goTemplateData(params map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(params))
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	// Sorting ensures the conflicts are resolved deterministically: a parameter wins over the parameters nested
+	// under its name, e.g. "path" over "path.basename"
+	sort.Strings(keys)
+
+	// The values are copied, since nesting the dotted parameters may add to the maps of nested parameters, which
+	// are shared with the generators
+	for _, key := range keys {
+		data[key] = copyValue(params[key])
+	}
+
+	for _, key := range keys {
+		if !strings.Contains(key, ".") {
+			continue
+		}
+		setNestedValue(data, strings.Split(key, "."), params[key])
+	}
+
+	return data
+}
+
+// copyValue returns a deep copy of the maps and slices of a parameter value.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = copyValue(item)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = copyValue(item)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// setNestedValue sets value under the path of nested maps, unless a non-map value is already in the way.
+func setNestedValue(data map[string]interface{}, path []string, value interface{}) {
+	current := data
+	for _, segment := range path[:len(path)-1] {
+		next, found := current[segment]
+		if !found {
+			nextMap := map[string]interface{}{}
+			current[segment] = nextMap
+			current = nextMap
+			continue
+		}
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = nextMap
+	}
+
+	last := path[len(path)-1]
+	if _, found := current[last]; !found {
+		current[last] = value
+	}
+}
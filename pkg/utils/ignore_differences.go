@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// defaultPreservedAnnotations are the annotations set on Applications by Argo CD itself, always preserved.
+var defaultPreservedAnnotations = []string{
+	"notified.notifications.argoproj.io",
+	"argocd.argoproj.io/refresh",
+}
+
+// wildcard is the path segment selecting every element of a list, or every key of a map.
+const wildcard = "[]"
+
+// MergeLiveApplication returns the generated Application, with the fields that are managed outside of the
+// ApplicationSet taken from the live Application: the preserved annotations and labels, and the ignored differences
+// of the spec.
+func MergeLiveApplication(applicationSet *argoprojiov1alpha1.ApplicationSet, generated, live *argov1alpha1.Application) (*argov1alpha1.Application, error) {
+	res := generated.DeepCopy()
+
+	preservedAnnotations, preservedLabels := getPreservedKeys(applicationSet)
+	res.Annotations = preserveKeys(res.Annotations, live.Annotations, preservedAnnotations)
+	res.Labels = preserveKeys(res.Labels, live.Labels, preservedLabels)
+
+	var paths [][]string
+	for _, ignore := range applicationSet.Spec.IgnoreApplicationDifferences {
+		if ignore.Name != "" && ignore.Name != generated.Name {
+			continue
+		}
+		for _, pointer := range ignore.JSONPointers {
+			path, err := parseJSONPointer(pointer)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+		for _, expression := range ignore.JQPathExpressions {
+			path, err := parseJQPath(expression)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return res, nil
+	}
+
+	generatedSpec, err := toJSONValue(res.Spec)
+	if err != nil {
+		return nil, err
+	}
+	liveSpec, err := toJSONValue(live.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		// Only the spec is merged, the annotations and labels are preserved by their keys
+		if len(path) == 0 || path[0] != "spec" {
+			continue
+		}
+		generatedSpec, _ = copyPath(liveSpec, true, generatedSpec, true, path[1:])
+	}
+
+	specBytes, err := json.Marshal(generatedSpec)
+	if err != nil {
+		return nil, err
+	}
+	res.Spec = argov1alpha1.ApplicationSpec{}
+	if err := json.Unmarshal(specBytes, &res.Spec); err != nil {
+		return nil, fmt.Errorf("invalid spec after merging the ignored differences: %w", err)
+	}
+
+	return res, nil
+}
+
+// HashApplication returns the hash of the fields of the Application managed by the ApplicationSet: its spec,
+// annotations and labels, without the preserved fields, the ignored differences and the AnnotationApplicationHash.
+func HashApplication(applicationSet *argoprojiov1alpha1.ApplicationSet, app *argov1alpha1.Application) (string, error) {
+	// Merging with an empty live Application removes the ignored differences from the spec
+	managed, err := MergeLiveApplication(applicationSet, app, &argov1alpha1.Application{})
+	if err != nil {
+		return "", err
+	}
+
+	preservedAnnotations, preservedLabels := getPreservedKeys(applicationSet)
+	fields := struct {
+		Spec        argov1alpha1.ApplicationSpec `json:"spec"`
+		Annotations map[string]string            `json:"annotations,omitempty"`
+		Labels      map[string]string            `json:"labels,omitempty"`
+	}{
+		Spec:        managed.Spec,
+		Annotations: withoutKeys(managed.Annotations, append(preservedAnnotations, argoprojiov1alpha1.AnnotationApplicationHash)),
+		Labels:      withoutKeys(managed.Labels, preservedLabels),
+	}
+
+	fieldsBytes, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(fieldsBytes)), nil
+}
+
+// getPreservedKeys returns the keys of the annotations and labels preserved by the ApplicationSet.
+func getPreservedKeys(applicationSet *argoprojiov1alpha1.ApplicationSet) ([]string, []string) {
+	preserved := applicationSet.Spec.PreservedFields
+	if preserved == nil {
+		return defaultPreservedAnnotations, nil
+	}
+	return append(append([]string{}, defaultPreservedAnnotations...), preserved.Annotations...), preserved.Labels
+}
+
+// withoutKeys returns a copy of the map without the given keys.
+func withoutKeys(values map[string]string, keys []string) map[string]string {
+	res := make(map[string]string, len(values))
+	for key, value := range values {
+		res[key] = value
+	}
+	for _, key := range keys {
+		delete(res, key)
+	}
+	return res
+}
+
+// preserveKeys returns the generated map, with the live values of the preserved keys.
+func preserveKeys(generated, live map[string]string, preserved []string) map[string]string {
+	var res map[string]string
+	if generated != nil {
+		res = make(map[string]string, len(generated))
+		for key, value := range generated {
+			res[key] = value
+		}
+	}
+
+	for _, key := range preserved {
+		value, found := live[key]
+		if !found {
+			continue
+		}
+		if res == nil {
+			res = map[string]string{}
+		}
+		res[key] = value
+	}
+
+	return res
+}
+
+func toJSONValue(value interface{}) (interface{}, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(valueBytes, &res)
+	return res, err
+}
+
+// copyPath returns the target value with the value at path taken from the source value, or removed if the source
+// doesn't have one. Elements of lists are replaced but never added or removed, as their order is significant.
+func copyPath(source interface{}, sourceFound bool, target interface{}, targetFound bool, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return source, sourceFound
+	}
+	head, tail := path[0], path[1:]
+
+	switch t := target.(type) {
+	case map[string]interface{}:
+		s, _ := source.(map[string]interface{})
+		keys := []string{head}
+		if head == wildcard {
+			keys = nil
+			for key := range t {
+				keys = append(keys, key)
+			}
+			for key := range s {
+				if _, found := t[key]; !found {
+					keys = append(keys, key)
+				}
+			}
+		}
+		for _, key := range keys {
+			sourceValue, sourceFound := s[key]
+			targetValue, targetFound := t[key]
+			if value, found := copyPath(sourceValue, sourceFound, targetValue, targetFound, tail); found {
+				t[key] = value
+			} else {
+				delete(t, key)
+			}
+		}
+		return t, true
+
+	case []interface{}:
+		s, _ := source.([]interface{})
+		for i := range t {
+			if head != wildcard && head != strconv.Itoa(i) {
+				continue
+			}
+			if i >= len(s) {
+				continue
+			}
+			if value, found := copyPath(s[i], true, t[i], true, tail); found {
+				t[i] = value
+			}
+		}
+		return t, true
+	}
+
+	// The target doesn't have the parent of the value: it is created if the source has the value
+	if s, ok := source.(map[string]interface{}); ok && head != wildcard {
+		if value, found := copyPath(s[head], containsKey(s, head), nil, false, tail); found {
+			return map[string]interface{}{head: value}, true
+		}
+	}
+	return target, targetFound
+}
+
+func containsKey(m map[string]interface{}, key string) bool {
+	_, found := m[key]
+	return found
+}
+
+// parseJSONPointer returns the path of a JSON pointer, e.g. /spec/source/targetRevision.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: it must start with /", pointer)
+	}
+
+	path := strings.Split(pointer[1:], "/")
+	for i := range path {
+		path[i] = strings.ReplaceAll(strings.ReplaceAll(path[i], "~1", "/"), "~0", "~")
+	}
+	return path, nil
+}
+
+// parseJQPath returns the path of a JQ-like path expression: fields are selected with .name or ["name"], list
+// elements with [index], and every element of a list with [].
+func parseJQPath(expression string) ([]string, error) {
+	var path []string
+
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid path expression %q: %s", expression, reason)
+	}
+
+	if !strings.HasPrefix(expression, ".") {
+		return nil, invalid("it must start with .")
+	}
+
+	for rest := expression; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, ".["):
+			rest = rest[1:]
+
+		case rest[0] == '.':
+			end := 1
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			if end == 1 {
+				return nil, invalid("empty field name")
+			}
+			path = append(path, rest[1:end])
+			rest = rest[end:]
+
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, invalid("unterminated [")
+			}
+			selector := rest[1:end]
+			switch {
+			case selector == "":
+				path = append(path, wildcard)
+			case strings.HasPrefix(selector, `"`):
+				field, err := strconv.Unquote(selector)
+				if err != nil {
+					return nil, invalid(fmt.Sprintf("invalid field name %s", selector))
+				}
+				path = append(path, field)
+			default:
+				if _, err := strconv.Atoi(selector); err != nil {
+					return nil, invalid(fmt.Sprintf("invalid index %s", selector))
+				}
+				path = append(path, selector)
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest))
+		}
+	}
+
+	return path, nil
+}
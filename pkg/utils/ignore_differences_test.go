@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestMergeLiveApplication(t *testing.T) {
+	generated := argov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Annotations: map[string]string{"generated": "true"},
+			Labels:      map[string]string{"env": "prod", "team": "payments"},
+		},
+		Spec: argov1alpha1.ApplicationSpec{
+			Project: "default",
+			Source: argov1alpha1.ApplicationSource{
+				RepoURL:        "https://github.com/argoproj/argocd-example-apps",
+				TargetRevision: "HEAD",
+				Helm: &argov1alpha1.ApplicationSourceHelm{
+					Parameters: []argov1alpha1.HelmParameter{{Name: "image.tag", Value: "v1"}, {Name: "replicas", Value: "1"}},
+				},
+			},
+			SyncPolicy: &argov1alpha1.SyncPolicy{Automated: &argov1alpha1.SyncPolicyAutomated{Prune: true}},
+		},
+	}
+
+	live := argov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Annotations: map[string]string{"argocd.argoproj.io/refresh": "hard", "external": "value", "other": "value"},
+			Labels:      map[string]string{"team": "platform", "owner": "me"},
+		},
+		Spec: argov1alpha1.ApplicationSpec{
+			Project: "other",
+			Source: argov1alpha1.ApplicationSource{
+				RepoURL:        "https://github.com/argoproj/argocd-example-apps",
+				TargetRevision: "v1.0.0",
+				Helm: &argov1alpha1.ApplicationSourceHelm{
+					Parameters: []argov1alpha1.HelmParameter{{Name: "image.tag", Value: "v2"}, {Name: "replicas", Value: "3"}},
+				},
+			},
+		},
+	}
+
+	for _, c := range []struct {
+		name     string
+		spec     argoprojiov1alpha1.ApplicationSetSpec
+		expected func(app *argov1alpha1.Application)
+	}{
+		{
+			name: "default preserved annotations",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard"}
+			},
+		},
+		{
+			name: "preserved annotations and labels",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{
+				PreservedFields: &argoprojiov1alpha1.ApplicationPreservedFields{
+					Annotations: []string{"external", "missing"},
+					Labels:      []string{"team"},
+				},
+			},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard", "external": "value"}
+				app.Labels = map[string]string{"env": "prod", "team": "platform"}
+			},
+		},
+		{
+			name: "json pointers",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{
+				IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+					{JSONPointers: []string{"/spec/source/targetRevision", "/spec/syncPolicy", "/metadata/labels"}},
+				},
+			},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard"}
+				app.Spec.Source.TargetRevision = "v1.0.0"
+				app.Spec.SyncPolicy = nil
+			},
+		},
+		{
+			name: "jq path expressions",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{
+				IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+					{JQPathExpressions: []string{`.spec.source.helm.parameters[].value`, `.spec["project"]`}},
+				},
+			},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard"}
+				app.Spec.Project = "other"
+				app.Spec.Source.Helm.Parameters = []argov1alpha1.HelmParameter{{Name: "image.tag", Value: "v2"}, {Name: "replicas", Value: "3"}}
+			},
+		},
+		{
+			name: "jq path expression on a list element",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{
+				IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+					{JQPathExpressions: []string{`.spec.source.helm.parameters[1]`}},
+				},
+			},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard"}
+				app.Spec.Source.Helm.Parameters = []argov1alpha1.HelmParameter{{Name: "image.tag", Value: "v1"}, {Name: "replicas", Value: "3"}}
+			},
+		},
+		{
+			name: "differences of other applications",
+			spec: argoprojiov1alpha1.ApplicationSetSpec{
+				IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+					{Name: "other", JSONPointers: []string{"/spec/project"}},
+				},
+			},
+			expected: func(app *argov1alpha1.Application) {
+				app.Annotations = map[string]string{"generated": "true", "argocd.argoproj.io/refresh": "hard"}
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			appSet := argoprojiov1alpha1.ApplicationSet{Spec: c.spec}
+
+			got, err := MergeLiveApplication(&appSet, &generated, &live)
+			assert.NoError(t, err)
+
+			expected := generated.DeepCopy()
+			c.expected(expected)
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func TestMergeLiveApplicationAddsMissingFields(t *testing.T) {
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+				{JQPathExpressions: []string{".spec.source.helm.releaseName"}},
+			},
+		},
+	}
+	generated := argov1alpha1.Application{}
+	live := argov1alpha1.Application{
+		Spec: argov1alpha1.ApplicationSpec{
+			Source: argov1alpha1.ApplicationSource{Helm: &argov1alpha1.ApplicationSourceHelm{ReleaseName: "release"}},
+		},
+	}
+
+	got, err := MergeLiveApplication(&appSet, &generated, &live)
+	assert.NoError(t, err)
+	if assert.NotNil(t, got.Spec.Source.Helm) {
+		assert.Equal(t, "release", got.Spec.Source.Helm.ReleaseName)
+	}
+}
+
+func TestParsePaths(t *testing.T) {
+	for _, c := range []struct {
+		expression string
+		expected   []string
+	}{
+		{".spec.source", []string{"spec", "source"}},
+		{".spec.info[]", []string{"spec", "info", wildcard}},
+		{".spec.info[0].name", []string{"spec", "info", "0", "name"}},
+		{`.metadata.annotations["example.com/key"]`, []string{"metadata", "annotations", "example.com/key"}},
+		{`.["spec"].project`, []string{"spec", "project"}},
+	} {
+		got, err := parseJQPath(c.expression)
+		assert.NoError(t, err, c.expression)
+		assert.Equal(t, c.expected, got, c.expression)
+	}
+
+	for _, expression := range []string{"", "spec", ".spec.", ".spec[", ".spec[a]", `.spec["a]`} {
+		_, err := parseJQPath(expression)
+		assert.Error(t, err, expression)
+	}
+
+	got, err := parseJSONPointer("/metadata/annotations/example.com~1key~0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata", "annotations", "example.com/key~"}, got)
+
+	_, err = parseJSONPointer("spec")
+	assert.Error(t, err)
+}
+
+func TestHashApplication(t *testing.T) {
+	appSet := argoprojiov1alpha1.ApplicationSet{
+		Spec: argoprojiov1alpha1.ApplicationSetSpec{
+			PreservedFields: &argoprojiov1alpha1.ApplicationPreservedFields{Labels: []string{"team"}},
+			IgnoreApplicationDifferences: argoprojiov1alpha1.ApplicationSetIgnoreDifferences{
+				{JSONPointers: []string{"/spec/source/targetRevision"}},
+			},
+		},
+	}
+	app := argov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Annotations: map[string]string{"generated": "true"},
+			Labels:      map[string]string{"env": "prod"},
+		},
+		Spec: argov1alpha1.ApplicationSpec{
+			Project: "default",
+			Source:  argov1alpha1.ApplicationSource{RepoURL: "https://github.com/argoproj/argocd-example-apps", TargetRevision: "HEAD"},
+		},
+	}
+
+	hash, err := HashApplication(&appSet, &app)
+	assert.NoError(t, err)
+
+	for _, c := range []struct {
+		name    string
+		change  func(app *argov1alpha1.Application)
+		changed bool
+	}{
+		{"hash annotation", func(app *argov1alpha1.Application) {
+			app.Annotations[argoprojiov1alpha1.AnnotationApplicationHash] = hash
+		}, false},
+		{"default preserved annotation", func(app *argov1alpha1.Application) { app.Annotations["argocd.argoproj.io/refresh"] = "hard" }, false},
+		{"preserved label", func(app *argov1alpha1.Application) { app.Labels["team"] = "platform" }, false},
+		{"ignored difference", func(app *argov1alpha1.Application) { app.Spec.Source.TargetRevision = "v1.0.0" }, false},
+		{"annotation", func(app *argov1alpha1.Application) { app.Annotations["generated"] = "false" }, true},
+		{"label", func(app *argov1alpha1.Application) { app.Labels["env"] = "dev" }, true},
+		{"spec", func(app *argov1alpha1.Application) { app.Spec.Project = "other" }, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			changed := app.DeepCopy()
+			c.change(changed)
+
+			got, err := HashApplication(&appSet, changed)
+			assert.NoError(t, err)
+			assert.Equal(t, c.changed, got != hash)
+		})
+	}
+}
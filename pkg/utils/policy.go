@@ -11,6 +11,25 @@ var Policies = map[string]Policy{
 	"sync":          &SyncPolicy{},
 	"create-only":   &CreateOnlyPolicy{},
 	"create-update": &CreateUpdatePolicy{},
+	"create-delete": &CreateDeletePolicy{},
+}
+
+// RestrictPolicy returns a policy allowing only the changes allowed by both policy and limit.
+func RestrictPolicy(policy, limit Policy) Policy {
+	return &restrictedPolicy{policy: policy, limit: limit}
+}
+
+type restrictedPolicy struct {
+	policy Policy
+	limit  Policy
+}
+
+func (p *restrictedPolicy) Update() bool {
+	return p.policy.Update() && p.limit.Update()
+}
+
+func (p *restrictedPolicy) Delete() bool {
+	return p.policy.Delete() && p.limit.Delete()
 }
 
 type SyncPolicy struct{}
@@ -33,6 +52,16 @@ func (p *CreateUpdatePolicy) Delete() bool {
 	return false
 }
 
+type CreateDeletePolicy struct{}
+
+func (p *CreateDeletePolicy) Update() bool {
+	return false
+}
+
+func (p *CreateDeletePolicy) Delete() bool {
+	return true
+}
+
 type CreateOnlyPolicy struct{}
 
 func (p *CreateOnlyPolicy) Update() bool {
@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// templateFunctions is the function library available to Go templates. The functions are named and take their
+// arguments in the same order as their Sprig (http://masterminds.github.io/sprig/) counterparts, so that templates
+// can be shared with Helm charts and other Sprig based tools.
+var templateFunctions = template.FuncMap{
+	// Strings
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"title":      strings.Title,
+	"trim":       strings.TrimSpace,
+	"trimAll":    func(cutset, s string) string { return strings.Trim(s, cutset) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"trunc":      trunc,
+	"substr":     substr,
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"quote":      func(s interface{}) string { return strconv.Quote(toString(s)) },
+	"squote":     func(s interface{}) string { return "'" + toString(s) + "'" },
+	"kebabcase":  func(s string) string { return separateWords(s, '-') },
+	"snakecase":  func(s string) string { return separateWords(s, '_') },
+	"toString":   toString,
+
+	// Lists
+	"list":      func(items ...interface{}) []interface{} { return items },
+	"join":      join,
+	"splitList": func(sep, s string) []string { return strings.Split(s, sep) },
+
+	// Maps
+	"get": get,
+	"dig": dig,
+
+	// Defaults and conditionals
+	"default":  defaultValue,
+	"empty":    empty,
+	"coalesce": coalesce,
+	"ternary": func(trueValue, falseValue interface{}, condition bool) interface{} {
+		if condition {
+			return trueValue
+		}
+		return falseValue
+	},
+
+	// Regular expressions
+	"regexMatch":      func(regex, s string) (bool, error) { return regexp.MatchString(regex, s) },
+	"regexReplaceAll": regexReplaceAll,
+
+	// Encoding
+	"toJson":    toJSON,
+	"b64enc":    func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"b64dec":    b64dec,
+	"sha1sum":   sha1sum,
+	"sha256sum": sha256sum,
+
+	// Numbers
+	"atoi": func(s string) (int, error) { return strconv.Atoi(s) },
+	"add":  func(a, b interface{}) int64 { return toInt64(a) + toInt64(b) },
+	"sub":  func(a, b interface{}) int64 { return toInt64(a) - toInt64(b) },
+}
+
+// trunc truncates s to length characters. A negative length keeps the last -length characters instead.
+func trunc(length int, s string) string {
+	if length < 0 && len(s)+length > 0 {
+		return s[len(s)+length:]
+	}
+	if length >= 0 && len(s) > length {
+		return s[:length]
+	}
+	return s
+}
+
+// substr returns the characters of s from start (inclusive) to end (exclusive). A negative end means the end of s.
+func substr(start, end int, s string) string {
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 || end > len(s) {
+		end = len(s)
+	}
+	if start > end {
+		return ""
+	}
+	return s[start:end]
+}
+
+func join(sep string, v interface{}) string {
+	items := toStringSlice(v)
+	return strings.Join(items, sep)
+}
+
+func toStringSlice(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		res := make([]string, 0, len(list))
+		for _, item := range list {
+			if item != nil {
+				res = append(res, toString(item))
+			}
+		}
+		return res
+	default:
+		val := reflect.ValueOf(v)
+		if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+			res := make([]string, 0, val.Len())
+			for i := 0; i < val.Len(); i++ {
+				res = append(res, toString(val.Index(i).Interface()))
+			}
+			return res
+		}
+		return []string{toString(v)}
+	}
+}
+
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case nil:
+		return ""
+	case []byte:
+		return string(s)
+	case fmt.Stringer:
+		return s.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// empty returns true if the value is the zero value of its type, or an empty collection.
+func empty(given interface{}) bool {
+	if given == nil {
+		return true
+	}
+	val := reflect.ValueOf(given)
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return val.Len() == 0
+	case reflect.Bool:
+		return !val.Bool()
+	case reflect.Complex64, reflect.Complex128:
+		return val.Complex() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return val.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return val.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return val.IsNil()
+	default:
+		return false
+	}
+}
+
+// get returns the value of the key of the map, or "" if the key is missing, e.g. {{ get . "optional" }}. Unlike
+// {{ .optional }}, it doesn't fail the rendering when the parameter is missing.
+func get(m map[string]interface{}, key string) interface{} {
+	if value, found := m[key]; found {
+		return value
+	}
+	return ""
+}
+
+// dig returns the value under the path of keys within nested maps, or a default value if any key along the path is
+// missing. The arguments are the keys, the default value, then the map, e.g. {{ dig "values" "env" "dev" . }}.
+func dig(args ...interface{}) (interface{}, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("dig needs at least one key, a default value and a map, got %d arguments", len(args))
+	}
+	keys, defaultValue := args[:len(args)-2], args[len(args)-2]
+	current, ok := args[len(args)-1].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("dig needs a map as its last argument, got %T", args[len(args)-1])
+	}
+
+	for i, key := range keys {
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("dig needs string keys, got %T", key)
+		}
+		value, found := current[keyString]
+		if !found {
+			return defaultValue, nil
+		}
+		if i == len(keys)-1 {
+			return value, nil
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return defaultValue, nil
+		}
+	}
+	return defaultValue, nil
+}
+
+// defaultValue returns given, or defaultValue if given is empty. given is optional, so that default can end a
+// pipeline. Templates fail on missing parameters before default is called, so optional parameters are read with
+// get or dig, e.g. {{ get . "folder" | default "apps" }}.
+func defaultValue(defaultValue interface{}, given ...interface{}) interface{} {
+	if len(given) == 0 || empty(given[0]) {
+		return defaultValue
+	}
+	return given[0]
+}
+
+// coalesce returns the first non-empty value.
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+func regexReplaceAll(regex, s, repl string) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func b64dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func sha1sum(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// separateWords lowercases s, separating its words with sep. Words are delimited by spaces, dashes, underscores
+// and lower to upper case changes, e.g. "fooBar baz" becomes "foo-bar-baz" with '-'.
+func separateWords(s string, sep rune) string {
+	var words []string
+	var word strings.Builder
+	prevLower := false
+
+	for _, r := range s {
+		if r == ' ' || r == '-' || r == '_' {
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			prevLower = false
+			continue
+		}
+		if unicode.IsUpper(r) && prevLower && word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+		word.WriteRune(unicode.ToLower(r))
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+
+	return strings.Join(words, string(sep))
+}
+
+// toInt64 converts any number, or numeric string, to an int64. Numbers of parameters read from JSON or YAML files
+// are float64, and are truncated. Values that aren't numbers convert to 0.
+func toInt64(v interface{}) int64 {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return int64(value.Float())
+	case reflect.String:
+		i, err := strconv.ParseInt(value.String(), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return i
+	default:
+		return 0
+	}
+}
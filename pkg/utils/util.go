@@ -4,21 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 
 	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/jeremywohl/flatten"
 	"github.com/pkg/errors"
 	"github.com/valyala/fasttemplate"
 )
 
 type Renderer interface {
-	RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]string) (*argov1alpha1.Application, error)
+	// RenderTemplateParams renders the Application template with the given parameters. useGoTemplate selects
+	// text/template rendering, where parameters keep their types and nesting, instead of the default fasttemplate
+	// {{param}} substitution of flattened string parameters.
+	RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]interface{}, useGoTemplate bool) (*argov1alpha1.Application, error)
 }
 
 type Render struct {
 }
 
-func (r *Render) RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]string) (*argov1alpha1.Application, error) {
+func (r *Render) RenderTemplateParams(tmpl *argov1alpha1.Application, params map[string]interface{}, useGoTemplate bool) (*argov1alpha1.Application, error) {
 	if tmpl == nil {
 		return nil, fmt.Errorf("Application template is empty ")
 	}
@@ -32,14 +37,27 @@ func (r *Render) RenderTemplateParams(tmpl *argov1alpha1.Application, params map
 		return nil, err
 	}
 
-	fstTmpl := fasttemplate.New(string(tmplBytes), "{{", "}}")
-	replacedTmplStr, err := r.replace(fstTmpl, params, true)
-	if err != nil {
-		return nil, err
+	var replacedTmplBytes []byte
+	if useGoTemplate {
+		replacedTmplBytes, err = r.renderGoTemplate(tmplBytes, params)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		stringParams, err := FlattenParameters(params)
+		if err != nil {
+			return nil, err
+		}
+		fstTmpl := fasttemplate.New(string(tmplBytes), "{{", "}}")
+		replacedTmplStr, err := r.replace(fstTmpl, stringParams, true)
+		if err != nil {
+			return nil, err
+		}
+		replacedTmplBytes = []byte(replacedTmplStr)
 	}
 
 	var replacedTmpl argov1alpha1.Application
-	err = json.Unmarshal([]byte(replacedTmplStr), &replacedTmpl)
+	err = json.Unmarshal(replacedTmplBytes, &replacedTmpl)
 	if err != nil {
 		return nil, err
 	}
@@ -80,3 +98,52 @@ func (r *Render) replace(fstTmpl *fasttemplate.Template, replaceMap map[string]s
 
 	return replacedTmpl, nil
 }
+
+// CombineMaps merges two parameter maps into a new one. A key present in both maps is only allowed
+// if both maps hold the same value for it, otherwise an error is returned.
+func CombineMaps(a map[string]interface{}, b map[string]interface{}) (map[string]interface{}, error) {
+
+	res := map[string]interface{}{}
+
+	for k, v := range a {
+		res[k] = v
+	}
+
+	for k, v := range b {
+		current, present := res[k]
+		if present && !reflect.DeepEqual(current, v) {
+			return nil, fmt.Errorf("found duplicate key %s with different value, a: %v, b: %v", k, current, v)
+		}
+		res[k] = v
+	}
+
+	return res, nil
+}
+
+// FlattenParameters flattens typed, possibly nested parameters into string parameters, as used by the
+// fasttemplate rendering: nested keys are joined with dots ("values.foo", "list.0") and non-string values
+// are formatted as JSON.
+func FlattenParameters(params map[string]interface{}) (map[string]string, error) {
+	flat, err := flatten.Flatten(params, "", flatten.DotStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string, len(flat))
+	for key, value := range flat {
+		switch v := value.(type) {
+		case string:
+			res[key] = v
+		case nil:
+			res[key] = ""
+		default:
+			valueJSON, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format parameter %s: %v", key, err)
+			}
+			res[key] = string(valueJSON)
+		}
+	}
+
+	return res, nil
+}
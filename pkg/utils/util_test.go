@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"testing"
+
+	argov1alpha1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRenderTemplateParams(t *testing.T) {
+
+	tmpl := func(name string, path string, labels map[string]string) *argov1alpha1.Application {
+		return &argov1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+			Spec: argov1alpha1.ApplicationSpec{
+				Source: argov1alpha1.ApplicationSource{
+					Path: path,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		template      *argov1alpha1.Application
+		params        map[string]interface{}
+		useGoTemplate bool
+		expectedName  string
+		expectedPath  string
+		expectedErr   bool
+	}{
+		{
+			name:         "fasttemplate substitution",
+			template:     tmpl("{{cluster}}-guestbook", "{{path}}", nil),
+			params:       map[string]interface{}{"cluster": "prod", "path": "apps/guestbook"},
+			expectedName: "prod-guestbook",
+			expectedPath: "apps/guestbook",
+		},
+		{
+			name:         "fasttemplate keeps unresolved parameters",
+			template:     tmpl("{{cluster}}-{{missing}}", "", nil),
+			params:       map[string]interface{}{"cluster": "prod"},
+			expectedName: "prod-{{missing}}",
+		},
+		{
+			name:          "go template with functions",
+			template:      tmpl(`{{ .cluster | lower | trunc 4 }}-{{ replace "/" "-" .path }}`, `{{ .values.folder | default "apps" }}/{{ index . "path.basename" }}`, nil),
+			params:        map[string]interface{}{"cluster": "PRODUCTION", "path": "apps/guestbook", "path.basename": "guestbook", "values.folder": ""},
+			useGoTemplate: true,
+			expectedName:  "prod-apps-guestbook",
+			expectedPath:  "apps/guestbook",
+		},
+		{
+			name:          "go template defaults for missing parameters",
+			template:      tmpl(`{{ get . "suffix" | default "guestbook" }}`, `{{ dig "values" "folder" "apps" . }}/{{ dig "values" "app" "guestbook" . }}`, nil),
+			params:        map[string]interface{}{"values.app": "guestbook"},
+			useGoTemplate: true,
+			expectedName:  "guestbook",
+			expectedPath:  "apps/guestbook",
+		},
+		{
+			name:          "go template default fails on missing parameters",
+			template:      tmpl(`{{ .values.folder | default "apps" }}`, "", nil),
+			params:        map[string]interface{}{"cluster": "prod"},
+			useGoTemplate: true,
+			expectedErr:   true,
+		},
+		{
+			name:          "go template conditionals",
+			template:      tmpl(`{{ if eq .env "prod" }}critical{{ else }}{{ .env }}{{ end }}-app`, "", nil),
+			params:        map[string]interface{}{"env": "prod"},
+			useGoTemplate: true,
+			expectedName:  "critical-app",
+		},
+		{
+			name:          "go template renders map keys",
+			template:      tmpl("app", "", map[string]string{"{{ .team }}/owner": "{{ .owner }}"}),
+			params:        map[string]interface{}{"team": "payments", "owner": "alice"},
+			useGoTemplate: true,
+			expectedName:  "app",
+		},
+		{
+			name:          "go template values are not JSON escaped",
+			template:      tmpl(`{{ .name }}`, "", nil),
+			params:        map[string]interface{}{"name": `quoted "name"`},
+			useGoTemplate: true,
+			expectedName:  `quoted "name"`,
+		},
+		{
+			name:         "fasttemplate flattens typed parameters",
+			template:     tmpl("{{cluster.name}}-{{replicas}}", "{{paths.0}}", nil),
+			params:       map[string]interface{}{"cluster": map[string]interface{}{"name": "prod"}, "replicas": float64(3), "paths": []interface{}{"apps/guestbook"}},
+			expectedName: "prod-3",
+			expectedPath: "apps/guestbook",
+		},
+		{
+			name:          "go template with typed parameters",
+			template:      tmpl(`{{ .cluster.name }}-{{ add .replicas 1 }}{{ if .enabled }}-enabled{{ end }}`, `{{ range .paths }}{{ . }}{{ end }}`, nil),
+			params:        map[string]interface{}{"cluster": map[string]interface{}{"name": "prod"}, "replicas": 3, "enabled": true, "paths": []interface{}{"apps/guestbook"}},
+			useGoTemplate: true,
+			expectedName:  "prod-4-enabled",
+			expectedPath:  "apps/guestbook",
+		},
+		{
+			name:          "go template fails on missing parameters",
+			template:      tmpl("{{ .missing }}", "", nil),
+			params:        map[string]interface{}{"cluster": "prod"},
+			useGoTemplate: true,
+			expectedErr:   true,
+		},
+		{
+			name:          "go template fails on invalid templates",
+			template:      tmpl("{{ .cluster ", "", nil),
+			params:        map[string]interface{}{"cluster": "prod"},
+			useGoTemplate: true,
+			expectedErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			render := Render{}
+			got, err := render.RenderTemplateParams(testCaseCopy.template, testCaseCopy.params, testCaseCopy.useGoTemplate)
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCaseCopy.expectedName, got.Name)
+			assert.Equal(t, testCaseCopy.expectedPath, got.Spec.Source.Path)
+			assert.Contains(t, got.Finalizers, "resources-finalizer.argocd.argoproj.io")
+			if testCaseCopy.template.Labels != nil {
+				assert.Equal(t, map[string]string{"payments/owner": "alice"}, got.Labels)
+			}
+		})
+	}
+}
+
+func TestTemplateFunctions(t *testing.T) {
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{`{{ "Hello World" | upper }}`, "HELLO WORLD"},
+		{`{{ "  padded  " | trim }}`, "padded"},
+		{`{{ trimPrefix "refs/heads/" "refs/heads/main" }}`, "main"},
+		{`{{ trunc -3 "release-1.2.3" }}`, "2.3"},
+		{`{{ substr 0 7 "release-1.2.3" }}`, "release"},
+		{`{{ "fooBar baz_qux" | kebabcase }}`, "foo-bar-baz-qux"},
+		{`{{ "fooBar baz-qux" | snakecase }}`, "foo_bar_baz_qux"},
+		{`{{ list "a" "b" "c" | join "," }}`, "a,b,c"},
+		{`{{ splitList "/" "a/b/c" | join "-" }}`, "a-b-c"},
+		{`{{ coalesce "" "first" "second" }}`, "first"},
+		{`{{ ternary "yes" "no" (hasPrefix "feat" "feature/x") }}`, "yes"},
+		{`{{ regexReplaceAll "[^a-z0-9]+" ("Feature/My_Branch" | lower) "-" }}`, "feature-my-branch"},
+		{`{{ "value" | b64enc }}`, "dmFsdWU="},
+		{`{{ "dmFsdWU=" | b64dec }}`, "value"},
+		{`{{ "value" | sha256sum | trunc 8 }}`, "cd42404d"},
+		{`{{ add (atoi "2") 3 }}`, "5"},
+		{`{{ list "a" 1 | toJson }}`, `["a",1]`},
+		{`{{ "x" | quote }}`, `"x"`},
+		{`{{ get . "missing" | default "fallback" }}`, "fallback"},
+		{`{{ dig "values" "missing" "fallback" . }}`, "fallback"},
+	}
+
+	for _, testCase := range testCases {
+		got, err := renderGoTemplateString(testCase.template, map[string]interface{}{})
+		assert.NoError(t, err, testCase.template)
+		assert.Equal(t, testCase.expected, got, testCase.template)
+	}
+}
+
+func TestFlattenParameters(t *testing.T) {
+	got, err := FlattenParameters(map[string]interface{}{
+		"name":    "app",
+		"empty":   nil,
+		"enabled": true,
+		"size":    float64(1000000),
+		"cluster": map[string]interface{}{
+			"name":    "prod",
+			"regions": []interface{}{"eu", "us"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"name":              "app",
+		"empty":             "",
+		"enabled":           "true",
+		"size":              "1000000",
+		"cluster.name":      "prod",
+		"cluster.regions.0": "eu",
+		"cluster.regions.1": "us",
+	}, got)
+}
+
+func TestCombineMaps(t *testing.T) {
+	testCases := []struct {
+		name        string
+		a           map[string]interface{}
+		b           map[string]interface{}
+		expected    map[string]interface{}
+		expectedErr bool
+	}{
+		{
+			name:     "distinct keys are combined",
+			a:        map[string]interface{}{"a": "1"},
+			b:        map[string]interface{}{"b": 2},
+			expected: map[string]interface{}{"a": "1", "b": 2},
+		},
+		{
+			name:     "identical nested values for the same key are combined",
+			a:        map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"c"}}},
+			b:        map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"c"}}},
+			expected: map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"c"}}},
+		},
+		{
+			name:        "different values for the same key return an error",
+			a:           map[string]interface{}{"a": map[string]interface{}{"b": "c"}},
+			b:           map[string]interface{}{"a": map[string]interface{}{"b": "d"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCaseCopy := testCase
+
+		t.Run(testCaseCopy.name, func(t *testing.T) {
+			got, err := CombineMaps(testCaseCopy.a, testCaseCopy.b)
+
+			if testCaseCopy.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCaseCopy.expected, got)
+		})
+	}
+}
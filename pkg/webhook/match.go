@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+// scpLikeURL matches the user@host:path form of the SSH repository URLs
+var scpLikeURL = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):(.*)$`)
+
+// shouldRefresh returns whether a generator of the ApplicationSet reads the repository of the event: a Git
+// generator for a push to the revision it reads, a pull request generator for a pull request event of its
+// repository, or an SCM provider generator for a push to a repository of its organization.
+func shouldRefresh(appSet *argoprojiov1alpha1.ApplicationSet, e *gitEvent) bool {
+	for _, generator := range getGenerators(appSet) {
+		if generator.Git != nil && gitGeneratorMatches(generator.Git, e) {
+			return true
+		}
+		if generator.PullRequest != nil && pullRequestGeneratorMatches(generator.PullRequest, e) {
+			return true
+		}
+		if generator.SCMProvider != nil && scmProviderGeneratorMatches(generator.SCMProvider, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// getGenerators returns the generators of the ApplicationSet, including the ones nested within matrix and merge
+// generators.
+func getGenerators(appSet *argoprojiov1alpha1.ApplicationSet) []*argoprojiov1alpha1.ApplicationSetGenerator {
+	var res []*argoprojiov1alpha1.ApplicationSetGenerator
+
+	for i := range appSet.Spec.Generators {
+		generator := &appSet.Spec.Generators[i]
+		res = append(res, generator)
+
+		var nestedGenerators []argoprojiov1alpha1.ApplicationSetNestedGenerator
+		if generator.Matrix != nil {
+			nestedGenerators = append(nestedGenerators, generator.Matrix.Generators...)
+		}
+		if generator.Merge != nil {
+			nestedGenerators = append(nestedGenerators, generator.Merge.Generators...)
+		}
+		for _, nestedGenerator := range nestedGenerators {
+			res = append(res, nestedGenerator.ToApplicationSetGenerator())
+		}
+	}
+
+	return res
+}
+
+func gitGeneratorMatches(generator *argoprojiov1alpha1.GitGenerator, e *gitEvent) bool {
+	if e.pullRequest {
+		return false
+	}
+
+	repoURL := normalizeRepoURL(generator.RepoURL)
+	for _, eventURL := range e.repoURLs {
+		if eventURL != "" && normalizeRepoURL(eventURL) == repoURL {
+			return revisionMatches(generator.Revision, e)
+		}
+	}
+	return false
+}
+
+// revisionMatches returns whether the revision read by a Git generator was pushed. HEAD is the default branch, which
+// is matched by any push when the provider doesn't send it.
+func revisionMatches(revision string, e *gitEvent) bool {
+	revision = strings.TrimPrefix(revision, "refs/heads/")
+	revision = strings.TrimPrefix(revision, "refs/tags/")
+
+	for _, pushed := range e.revisions {
+		if revision == "" || revision == "HEAD" {
+			if e.defaultBranch == "" || pushed == e.defaultBranch {
+				return true
+			}
+		} else if pushed == revision {
+			return true
+		}
+	}
+	return false
+}
+
+func pullRequestGeneratorMatches(generator *argoprojiov1alpha1.PullRequestGenerator, e *gitEvent) bool {
+	if !e.pullRequest {
+		return false
+	}
+
+	switch {
+	case generator.Github != nil:
+		return e.provider == providerGithub && strings.EqualFold(generator.Github.Owner+"/"+generator.Github.Repo, e.fullName)
+	case generator.GitLab != nil:
+		return e.provider == providerGitlab && (strings.EqualFold(generator.GitLab.Project, e.fullName) || generator.GitLab.Project == e.projectID)
+	case generator.Gitea != nil:
+		return e.provider == providerGitea && strings.EqualFold(generator.Gitea.Owner+"/"+generator.Gitea.Repo, e.fullName)
+	}
+	return false
+}
+
+// scmProviderGeneratorMatches returns whether a push may change the repositories or branches generated by the SCM
+// provider generator.
+func scmProviderGeneratorMatches(generator *argoprojiov1alpha1.SCMProviderGenerator, e *gitEvent) bool {
+	if e.pullRequest {
+		return false
+	}
+
+	index := strings.LastIndex(e.fullName, "/")
+	if index < 0 {
+		return false
+	}
+	owner := e.fullName[:index]
+
+	switch {
+	case generator.Github != nil:
+		return e.provider == providerGithub && strings.EqualFold(generator.Github.Organization, owner)
+	case generator.Gitlab != nil:
+		if e.provider != providerGitlab {
+			return false
+		}
+		group := strings.ToLower(generator.Gitlab.Group)
+		owner = strings.ToLower(owner)
+		return owner == group || (generator.Gitlab.IncludeSubgroups && strings.HasPrefix(owner, group+"/"))
+	case generator.Gitea != nil:
+		return e.provider == providerGitea && strings.EqualFold(generator.Gitea.Owner, owner)
+	}
+	return false
+}
+
+// normalizeRepoURL returns the host and path of a repository URL, so that its web, HTTPS and SSH URLs compare equal.
+func normalizeRepoURL(repoURL string) string {
+	repoURL = strings.ToLower(strings.TrimSpace(repoURL))
+
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		repoURL = parsed.Hostname() + parsed.Path
+	} else if match := scpLikeURL.FindStringSubmatch(repoURL); match != nil {
+		repoURL = match[1] + "/" + match[2]
+	}
+
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	repoURL = strings.TrimSuffix(repoURL, ".git")
+	return repoURL
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+func TestNormalizeRepoURL(t *testing.T) {
+	for _, repoURL := range []string{
+		"https://github.com/argoproj/argocd-example-apps",
+		"https://github.com/argoproj/argocd-example-apps.git",
+		"https://github.com/argoproj/argocd-example-apps/",
+		"https://user@GitHub.com/argoproj/argocd-example-apps.git",
+		"git@github.com:argoproj/argocd-example-apps.git",
+		"ssh://git@github.com:22/argoproj/argocd-example-apps.git",
+	} {
+		assert.Equal(t, "github.com/argoproj/argocd-example-apps", normalizeRepoURL(repoURL), repoURL)
+	}
+}
+
+func TestRevisionMatches(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		revision string
+		event    gitEvent
+		expected bool
+	}{
+		{"HEAD on default branch", "HEAD", gitEvent{revisions: []string{"main"}, defaultBranch: "main"}, true},
+		{"HEAD on other branch", "HEAD", gitEvent{revisions: []string{"feature"}, defaultBranch: "main"}, false},
+		{"HEAD with unknown default branch", "", gitEvent{revisions: []string{"feature"}}, true},
+		{"branch", "refs/heads/feature", gitEvent{revisions: []string{"feature"}, defaultBranch: "main"}, true},
+		{"tag", "v1.0.0", gitEvent{revisions: []string{"v1.0.0"}, defaultBranch: "main"}, true},
+		{"other branch", "release", gitEvent{revisions: []string{"main"}, defaultBranch: "main"}, false},
+		{"no revision", "HEAD", gitEvent{}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, revisionMatches(c.revision, &c.event))
+		})
+	}
+}
+
+func TestSCMProviderGeneratorMatches(t *testing.T) {
+	gitlab := func(group string, includeSubgroups bool) *argoprojiov1alpha1.SCMProviderGenerator {
+		return &argoprojiov1alpha1.SCMProviderGenerator{
+			Gitlab: &argoprojiov1alpha1.SCMProviderGeneratorGitlab{Group: group, IncludeSubgroups: includeSubgroups},
+		}
+	}
+	push := &gitEvent{provider: providerGitlab, fullName: "group/subgroup/project"}
+
+	assert.True(t, scmProviderGeneratorMatches(gitlab("group/subgroup", false), push))
+	assert.False(t, scmProviderGeneratorMatches(gitlab("group", false), push))
+	assert.True(t, scmProviderGeneratorMatches(gitlab("group", true), push))
+	assert.False(t, scmProviderGeneratorMatches(gitlab("other", true), push))
+	assert.False(t, scmProviderGeneratorMatches(gitlab("group/subgroup", false), &gitEvent{provider: providerGitlab, fullName: "group/subgroup/project", pullRequest: true}))
+
+	github := &argoprojiov1alpha1.SCMProviderGenerator{Github: &argoprojiov1alpha1.SCMProviderGeneratorGithub{Organization: "Argoproj"}}
+	assert.True(t, scmProviderGeneratorMatches(github, &gitEvent{provider: providerGithub, fullName: "argoproj/argo-cd"}))
+	assert.False(t, scmProviderGeneratorMatches(github, &gitEvent{provider: providerGitea, fullName: "argoproj/argo-cd"}))
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type provider string
+
+const (
+	providerGithub    provider = "github"
+	providerGitlab    provider = "gitlab"
+	providerBitbucket provider = "bitbucket"
+	providerGitea     provider = "gitea"
+)
+
+// gitEvent is the provider independent description of a push or pull request webhook.
+type gitEvent struct {
+	provider provider
+	// repoURLs are the URLs of the repository, in the formats known to the provider (web, HTTPS, SSH)
+	repoURLs []string
+	// fullName is the path of the repository on the provider, e.g. owner/repo, or group/subgroup/project on GitLab
+	fullName string
+	// projectID is the numeric ID of the GitLab project, which can reference it instead of its path
+	projectID string
+	// revisions are the branches and tags that were pushed, without their refs/heads/ or refs/tags/ prefix
+	revisions     []string
+	defaultBranch string
+	pullRequest   bool
+}
+
+// githubRepository is the repository of the GitHub and Gitea payloads, which share the same format.
+type githubRepository struct {
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type githubPayload struct {
+	Ref        string           `json:"ref"`
+	Repository githubRepository `json:"repository"`
+}
+
+type gitlabPayload struct {
+	Ref     string `json:"ref"`
+	Project struct {
+		ID                int    `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+		GitHTTPURL        string `json:"git_http_url"`
+		GitSSHURL         string `json:"git_ssh_url"`
+		DefaultBranch     string `json:"default_branch"`
+	} `json:"project"`
+}
+
+type bitbucketRef struct {
+	Name string `json:"name"`
+}
+
+type bitbucketPayload struct {
+	Push struct {
+		Changes []struct {
+			New *bitbucketRef `json:"new"`
+			Old *bitbucketRef `json:"old"`
+		} `json:"changes"`
+	} `json:"push"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		Links    struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+		MainBranch *bitbucketRef `json:"mainbranch"`
+	} `json:"repository"`
+}
+
+// parse returns the event described by the payload, or nil if it is neither a push nor a pull request event.
+func parse(provider provider, header http.Header, payload []byte) (*gitEvent, error) {
+	switch provider {
+	case providerGithub, providerGitea:
+		eventType := header.Get("X-GitHub-Event")
+		if provider == providerGitea {
+			eventType = header.Get("X-Gitea-Event")
+		}
+		// Gitea sends pull_request, or pull_request_sync and the like with recent versions
+		pullRequest := strings.HasPrefix(eventType, "pull_request")
+		if eventType != "push" && !pullRequest {
+			return nil, nil
+		}
+
+		var p githubPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, err
+		}
+		return &gitEvent{
+			provider:      provider,
+			repoURLs:      []string{p.Repository.HTMLURL, p.Repository.CloneURL, p.Repository.SSHURL},
+			fullName:      p.Repository.FullName,
+			revisions:     getRevisions(p.Ref),
+			defaultBranch: p.Repository.DefaultBranch,
+			pullRequest:   pullRequest,
+		}, nil
+
+	case providerGitlab:
+		eventType := header.Get("X-Gitlab-Event")
+		pullRequest := eventType == "Merge Request Hook"
+		if eventType != "Push Hook" && eventType != "Tag Push Hook" && !pullRequest {
+			return nil, nil
+		}
+
+		var p gitlabPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, err
+		}
+		return &gitEvent{
+			provider:      provider,
+			repoURLs:      []string{p.Project.WebURL, p.Project.GitHTTPURL, p.Project.GitSSHURL},
+			fullName:      p.Project.PathWithNamespace,
+			projectID:     strconv.Itoa(p.Project.ID),
+			revisions:     getRevisions(p.Ref),
+			defaultBranch: p.Project.DefaultBranch,
+			pullRequest:   pullRequest,
+		}, nil
+
+	case providerBitbucket:
+		eventType := header.Get("X-Event-Key")
+		pullRequest := strings.HasPrefix(eventType, "pullrequest:")
+		if eventType != "repo:push" && !pullRequest {
+			return nil, nil
+		}
+
+		var p bitbucketPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, err
+		}
+		res := &gitEvent{
+			provider:    provider,
+			repoURLs:    []string{p.Repository.Links.HTML.Href},
+			fullName:    p.Repository.FullName,
+			pullRequest: pullRequest,
+		}
+		if p.Repository.MainBranch != nil {
+			res.defaultBranch = p.Repository.MainBranch.Name
+		}
+		for _, change := range p.Push.Changes {
+			// A deleted branch or tag only has an old ref, a created one only has a new ref
+			if change.New != nil {
+				res.revisions = append(res.revisions, change.New.Name)
+			} else if change.Old != nil {
+				res.revisions = append(res.revisions, change.Old.Name)
+			}
+		}
+		return res, nil
+	}
+
+	return nil, nil
+}
+
+// getRevisions returns the branch or tag name of a pushed ref.
+func getRevisions(ref string) []string {
+	if ref == "" {
+		return nil
+	}
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+	ref = strings.TrimPrefix(ref, "refs/tags/")
+	return []string{ref}
+}
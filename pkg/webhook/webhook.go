@@ -0,0 +1,238 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+	"github.com/argoproj-labs/applicationset/pkg/utils"
+)
+
+const (
+	// Path is the path on which the webhooks are received
+	Path = "/api/webhook"
+
+	// ArgoCDSecretName is the name of the Argo CD Secret holding the webhook secrets
+	ArgoCDSecretName = "argocd-secret"
+
+	// The keys of the webhook secrets in the Argo CD Secret, shared with the Argo CD API server
+	GithubSecretKey    = "webhook.github.secret"
+	GitlabSecretKey    = "webhook.gitlab.secret"
+	BitbucketSecretKey = "webhook.bitbucket.uuid"
+	GiteaSecretKey     = "webhook.gitea.secret"
+
+	// maxPayloadSize is the size above which payloads are refused, GitHub caps them to 25MB
+	maxPayloadSize = 25 * 1024 * 1024
+
+	// webhookEventsBufferSize is the number of pending requeue events, before the webhooks block on sending more
+	webhookEventsBufferSize = 1024
+)
+
+var errInvalidSignature = errors.New("invalid webhook signature")
+
+// WebhookHandler receives the push and pull request webhooks of GitHub, GitLab, Bitbucket Cloud and Gitea, and
+// requeues the ApplicationSets whose Git, pull request or SCM provider generators read the repository of the event,
+// instead of waiting for their next periodic requeue.
+//
+// The payloads are authenticated with the secrets of the Argo CD Secret, as configured for the Argo CD API server.
+// Webhooks of a provider without a configured secret are refused, unless allowUnauthenticated is set.
+type WebhookHandler struct {
+	client               client.Client
+	namespace            string
+	allowUnauthenticated bool
+	events               chan event.GenericEvent
+}
+
+// NewWebhookHandler returns a handler requeuing the ApplicationSets of namespace, which is also the namespace of the
+// Argo CD Secret. allowUnauthenticated accepts the webhooks of the providers without a configured secret.
+func NewWebhookHandler(c client.Client, namespace string, allowUnauthenticated bool) *WebhookHandler {
+	return &WebhookHandler{
+		client:               c,
+		namespace:            namespace,
+		allowUnauthenticated: allowUnauthenticated,
+		events:               make(chan event.GenericEvent, webhookEventsBufferSize),
+	}
+}
+
+// Events returns the channel on which the ApplicationSets to requeue are sent.
+func (h *WebhookHandler) Events() <-chan event.GenericEvent {
+	return h.events
+}
+
+// Serve serves the webhooks on addr, until ctx is done.
+func (h *WebhookHandler) Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, h)
+
+	log.WithField("addr", addr).Info("serving webhooks")
+	return utils.ListenAndServe(ctx, addr, mux)
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read the payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	provider := getProvider(r.Header)
+	if provider == "" {
+		http.Error(w, "unknown webhook provider", http.StatusBadRequest)
+		return
+	}
+
+	secrets, err := h.getSecrets(r.Context())
+	if err != nil {
+		log.WithError(err).Error("unable to read the webhook secrets")
+		http.Error(w, "unable to read the webhook secrets", http.StatusInternalServerError)
+		return
+	}
+
+	if err := validate(provider, r.Header, payload, secrets, h.allowUnauthenticated); err != nil {
+		log.WithField("provider", provider).WithError(err).Warn("refusing webhook")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	gitEvent, err := parse(provider, r.Header, payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse the payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if gitEvent == nil {
+		// Events such as pings don't change what the generators read
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	appSetList := &argoprojiov1alpha1.ApplicationSetList{}
+	if err := h.client.List(r.Context(), appSetList, client.InNamespace(h.namespace)); err != nil {
+		log.WithError(err).Error("unable to list ApplicationSets")
+		http.Error(w, "unable to list ApplicationSets", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range appSetList.Items {
+		appSet := &appSetList.Items[i]
+		if !shouldRefresh(appSet, gitEvent) {
+			continue
+		}
+
+		log.WithField("appSet", appSet.Name).WithField("repo", gitEvent.fullName).Info("requeuing ApplicationSet on webhook")
+		select {
+		case h.events <- event.GenericEvent{Object: appSet}:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// getSecrets returns the webhook secrets of the Argo CD Secret, which are all unset if the Secret doesn't exist.
+func (h *WebhookHandler) getSecrets(ctx context.Context) (map[string]string, error) {
+	secret := &corev1.Secret{}
+	err := h.client.Get(ctx, types.NamespacedName{Name: ArgoCDSecretName, Namespace: h.namespace}, secret)
+	if apierr.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+	for _, key := range []string{GithubSecretKey, GitlabSecretKey, BitbucketSecretKey, GiteaSecretKey} {
+		if value, ok := secret.Data[key]; ok {
+			res[key] = strings.TrimSpace(string(value))
+		}
+	}
+	return res, nil
+}
+
+// getProvider returns the provider that sent the webhook, guessed from its event header. Gitea also sends the event
+// headers of GitHub, so it is checked first.
+func getProvider(header http.Header) provider {
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		return providerGitea
+	case header.Get("X-GitHub-Event") != "":
+		return providerGithub
+	case header.Get("X-Gitlab-Event") != "":
+		return providerGitlab
+	case header.Get("X-Event-Key") != "" && header.Get("X-Hook-UUID") != "":
+		return providerBitbucket
+	}
+	return ""
+}
+
+// secretKeys are the keys of the webhook secrets of the providers in the Argo CD Secret.
+var secretKeys = map[provider]string{
+	providerGithub:    GithubSecretKey,
+	providerGitlab:    GitlabSecretKey,
+	providerBitbucket: BitbucketSecretKey,
+	providerGitea:     GiteaSecretKey,
+}
+
+// validate checks the signature or token of the webhook against the secret configured for its provider. Without a
+// configured secret, the webhook is refused unless allowUnauthenticated is set.
+func validate(provider provider, header http.Header, payload []byte, secrets map[string]string, allowUnauthenticated bool) error {
+	secret := secrets[secretKeys[provider]]
+	if secret == "" {
+		if allowUnauthenticated {
+			return nil
+		}
+		return fmt.Errorf("no %s webhook secret configured, set %s in the %s Secret", provider, secretKeys[provider], ArgoCDSecretName)
+	}
+
+	switch provider {
+	case providerGithub:
+		signature := header.Get("X-Hub-Signature-256")
+		if !strings.HasPrefix(signature, "sha256=") || !validHMAC(payload, secret, strings.TrimPrefix(signature, "sha256=")) {
+			return errInvalidSignature
+		}
+	case providerGitea:
+		if !validHMAC(payload, secret, header.Get("X-Gitea-Signature")) {
+			return errInvalidSignature
+		}
+	case providerGitlab:
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return errInvalidSignature
+		}
+	case providerBitbucket:
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Hook-UUID")), []byte(secret)) != 1 {
+			return errInvalidSignature
+		}
+	}
+	return nil
+}
+
+// validHMAC returns whether the hex encoded signature is the HMAC-SHA256 of the payload with the secret.
+func validHMAC(payload []byte, secret string, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	argoprojiov1alpha1 "github.com/argoproj-labs/applicationset/api/v1alpha1"
+)
+
+const (
+	githubPush = `{
+  "ref": "refs/heads/main",
+  "repository": {
+    "full_name": "argoproj/argocd-example-apps",
+    "html_url": "https://github.com/argoproj/argocd-example-apps",
+    "clone_url": "https://github.com/argoproj/argocd-example-apps.git",
+    "ssh_url": "git@github.com:argoproj/argocd-example-apps.git",
+    "default_branch": "main"
+  }
+}`
+	githubPullRequest = `{
+  "action": "opened",
+  "pull_request": {"number": 1},
+  "repository": {
+    "full_name": "argoproj/argocd-example-apps",
+    "html_url": "https://github.com/argoproj/argocd-example-apps",
+    "default_branch": "main"
+  }
+}`
+	gitlabMergeRequest = `{
+  "object_kind": "merge_request",
+  "project": {
+    "id": 15,
+    "path_with_namespace": "group/subgroup/project",
+    "web_url": "https://gitlab.com/group/subgroup/project",
+    "git_http_url": "https://gitlab.com/group/subgroup/project.git",
+    "git_ssh_url": "git@gitlab.com:group/subgroup/project.git",
+    "default_branch": "main"
+  }
+}`
+	bitbucketPush = `{
+  "push": {
+    "changes": [
+      {"new": {"type": "branch", "name": "feature"}, "old": {"type": "branch", "name": "feature"}},
+      {"new": null, "old": {"type": "tag", "name": "v1.0.0"}}
+    ]
+  },
+  "repository": {
+    "full_name": "team/repo",
+    "links": {"html": {"href": "https://bitbucket.org/team/repo"}}
+  }
+}`
+	giteaPush = `{
+  "ref": "refs/tags/v1.0.0",
+  "repository": {
+    "full_name": "org/repo",
+    "html_url": "https://gitea.example.com/org/repo",
+    "clone_url": "https://gitea.example.com/org/repo.git",
+    "ssh_url": "git@gitea.example.com:org/repo.git",
+    "default_branch": "main"
+  }
+}`
+)
+
+func hmacSHA256(payload string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := argoprojiov1alpha1.AddToScheme(scheme)
+	assert.Nil(t, err)
+	err = corev1.AddToScheme(scheme)
+	assert.Nil(t, err)
+
+	appSet := func(name string, generator argoprojiov1alpha1.ApplicationSetGenerator) client.Object {
+		return &argoprojiov1alpha1.ApplicationSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "argocd"},
+			Spec: argoprojiov1alpha1.ApplicationSetSpec{
+				Generators: []argoprojiov1alpha1.ApplicationSetGenerator{generator},
+			},
+		}
+	}
+
+	objects := []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ArgoCDSecretName, Namespace: "argocd"},
+			Data: map[string][]byte{
+				GithubSecretKey:    []byte("github-secret"),
+				GitlabSecretKey:    []byte("gitlab-secret"),
+				BitbucketSecretKey: []byte("{bitbucket-uuid}"),
+			},
+		},
+		appSet("git-main", argoprojiov1alpha1.ApplicationSetGenerator{
+			Git: &argoprojiov1alpha1.GitGenerator{RepoURL: "https://github.com/argoproj/argocd-example-apps.git", Revision: "HEAD"},
+		}),
+		appSet("git-other-branch", argoprojiov1alpha1.ApplicationSetGenerator{
+			Git: &argoprojiov1alpha1.GitGenerator{RepoURL: "https://github.com/argoproj/argocd-example-apps.git", Revision: "release"},
+		}),
+		appSet("pull-request", argoprojiov1alpha1.ApplicationSetGenerator{
+			PullRequest: &argoprojiov1alpha1.PullRequestGenerator{
+				Github: &argoprojiov1alpha1.PullRequestGeneratorGithub{Owner: "argoproj", Repo: "argocd-example-apps"},
+			},
+		}),
+		appSet("matrix-merge-request", argoprojiov1alpha1.ApplicationSetGenerator{
+			Matrix: &argoprojiov1alpha1.MatrixGenerator{
+				Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+					{List: &argoprojiov1alpha1.ListGenerator{}},
+					{PullRequest: &argoprojiov1alpha1.PullRequestGenerator{
+						GitLab: &argoprojiov1alpha1.PullRequestGeneratorGitLab{Project: "15"},
+					}},
+				},
+			},
+		}),
+		appSet("bitbucket", argoprojiov1alpha1.ApplicationSetGenerator{
+			Git: &argoprojiov1alpha1.GitGenerator{RepoURL: "git@bitbucket.org:team/repo.git", Revision: "v1.0.0"},
+		}),
+		appSet("gitea-scm", argoprojiov1alpha1.ApplicationSetGenerator{
+			SCMProvider: &argoprojiov1alpha1.SCMProviderGenerator{
+				Gitea: &argoprojiov1alpha1.SCMProviderGeneratorGitea{Owner: "org", API: "https://gitea.example.com"},
+			},
+		}),
+	}
+
+	for _, c := range []struct {
+		name                 string
+		headers              map[string]string
+		payload              string
+		allowUnauthenticated bool
+		expectedStatus       int
+		expected             []string
+	}{
+		{
+			name:           "github push",
+			headers:        map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + hmacSHA256(githubPush, "github-secret")},
+			payload:        githubPush,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"git-main"},
+		},
+		{
+			name:           "github invalid signature",
+			headers:        map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + hmacSHA256(githubPush, "other-secret")},
+			payload:        githubPush,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "github missing signature",
+			headers:        map[string]string{"X-GitHub-Event": "push"},
+			payload:        githubPush,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "github pull request",
+			headers:        map[string]string{"X-GitHub-Event": "pull_request", "X-Hub-Signature-256": "sha256=" + hmacSHA256(githubPullRequest, "github-secret")},
+			payload:        githubPullRequest,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"pull-request"},
+		},
+		{
+			name:           "github ping",
+			headers:        map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": "sha256=" + hmacSHA256("{}", "github-secret")},
+			payload:        "{}",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "gitlab merge request",
+			headers:        map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": "gitlab-secret"},
+			payload:        gitlabMergeRequest,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"matrix-merge-request"},
+		},
+		{
+			name:           "gitlab invalid token",
+			headers:        map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": "other-secret"},
+			payload:        gitlabMergeRequest,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "bitbucket push",
+			headers:        map[string]string{"X-Event-Key": "repo:push", "X-Hook-UUID": "{bitbucket-uuid}"},
+			payload:        bitbucketPush,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"bitbucket"},
+		},
+		{
+			name:           "gitea push without configured secret",
+			headers:        map[string]string{"X-Gitea-Event": "push", "X-GitHub-Event": "push"},
+			payload:        giteaPush,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:                 "gitea push without configured secret allowed unauthenticated",
+			headers:              map[string]string{"X-Gitea-Event": "push", "X-GitHub-Event": "push"},
+			payload:              giteaPush,
+			allowUnauthenticated: true,
+			expectedStatus:       http.StatusOK,
+			expected:             []string{"gitea-scm"},
+		},
+		{
+			name:                 "github invalid signature allowed unauthenticated",
+			headers:              map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + hmacSHA256(githubPush, "other-secret")},
+			payload:              githubPush,
+			allowUnauthenticated: true,
+			expectedStatus:       http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown provider",
+			headers:        map[string]string{},
+			payload:        githubPush,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid payload",
+			headers:        map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "gitlab-secret"},
+			payload:        "{",
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			h := NewWebhookHandler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), "argocd", c.allowUnauthenticated)
+
+			req := httptest.NewRequest(http.MethodPost, Path, bytes.NewBufferString(c.payload))
+			for key, value := range c.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.expectedStatus, rec.Code)
+
+			got := []string{}
+			for len(h.events) > 0 {
+				e := <-h.events
+				got = append(got, e.Object.GetName())
+			}
+			if c.expected == nil {
+				c.expected = []string{}
+			}
+			assert.ElementsMatch(t, c.expected, got)
+		})
+	}
+}
+
+func TestWebhookHandlerMethod(t *testing.T) {
+	h := NewWebhookHandler(fake.NewClientBuilder().Build(), "argocd", false)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}